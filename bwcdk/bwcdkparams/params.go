@@ -4,9 +4,16 @@
 // This package enables cross-region resource sharing in multi-region CDK deployments:
 //   - Primary region: Creates resources and stores identifiers in SSM Parameter Store
 //   - Secondary regions: Retrieves stored values to reference existing resources
+//
+// In multi-account apps, LookupFromAccount reads parameters of another account
+// through the role NewLookupRole creates there.
 package bwcdkparams
 
 import (
+	"fmt"
+
+	"github.com/aws/aws-cdk-go/awscdk/v2"
+	"github.com/aws/aws-cdk-go/awscdk/v2/awsiam"
 	"github.com/aws/aws-cdk-go/awscdk/v2/awsssm"
 	"github.com/aws/aws-cdk-go/awscdk/v2/customresources"
 	"github.com/aws/constructs-go/constructs/v10"
@@ -65,3 +72,82 @@ func Lookup(scope constructs.Construct, id string, namespace string, name string
 		})
 	return lookup.GetResponseField(jsii.String("Parameter.Value"))
 }
+
+// LookupRoleName returns the name of the role NewLookupRole creates in every
+// account that shares parameters.
+func LookupRoleName(scope constructs.Construct) string {
+	return bwcdkutil.Qualifier(scope) + "-param-lookup"
+}
+
+// NewLookupRole creates a role that the other accounts of the app may assume
+// to read this account's parameters under /{qualifier}/. Create it once per
+// account, in the primary shared stack; IAM roles are global. It returns nil
+// when the app has no other accounts.
+func NewLookupRole(scope constructs.Construct, id string) awsiam.Role {
+	stack := awscdk.Stack_Of(scope)
+	cfg := bwcdkutil.ConfigFromScope(scope)
+
+	var principals []awsiam.IPrincipal
+	for _, account := range cfg.Accounts() {
+		if account != "" && account != *stack.Account() {
+			principals = append(principals, awsiam.NewAccountPrincipal(jsii.String(account)))
+		}
+	}
+	if len(principals) == 0 {
+		return nil
+	}
+
+	role := awsiam.NewRole(scope, jsii.String(id), &awsiam.RoleProps{
+		RoleName:  jsii.String(LookupRoleName(scope)),
+		AssumedBy: awsiam.NewCompositePrincipal(principals...),
+	})
+	role.AddToPolicy(awsiam.NewPolicyStatement(&awsiam.PolicyStatementProps{
+		Actions: jsii.Strings("ssm:GetParameter"),
+		Resources: jsii.Strings(*stack.FormatArn(&awscdk.ArnComponents{
+			Service:      jsii.String("ssm"),
+			Region:       jsii.String("*"),
+			Resource:     jsii.String("parameter"),
+			ResourceName: jsii.String(fmt.Sprintf("%s/*", bwcdkutil.Qualifier(scope))),
+		})),
+	}))
+	return role
+}
+
+// LookupFromAccount retrieves a parameter stored in the primary region of
+// another account of the app, like Lookup. The custom resource assumes the
+// role NewLookupRole created in that account, so deploy the shared stacks of
+// that account first.
+func LookupFromAccount(
+	scope constructs.Construct, id string, account string, namespace string, name string, physicalID string,
+) *string {
+	roleARN := awscdk.Stack_Of(scope).FormatArn(&awscdk.ArnComponents{
+		Service:      jsii.String("iam"),
+		Region:       jsii.String(""),
+		Account:      jsii.String(account),
+		Resource:     jsii.String("role"),
+		ResourceName: jsii.String(LookupRoleName(scope)),
+	})
+	sdkCall := &customresources.AwsSdkCall{
+		Service: jsii.String("SSM"),
+		Action:  jsii.String("getParameter"),
+		Parameters: map[string]any{
+			"Name": ParameterName(scope, namespace, name),
+		},
+		Region:             jsii.String(bwcdkutil.PrimaryRegion(scope)),
+		AssumedRoleArn:     roleARN,
+		PhysicalResourceId: customresources.PhysicalResourceId_Of(jsii.String(physicalID)),
+	}
+	// See Lookup for why OnUpdate is set.
+	lookup := customresources.NewAwsCustomResource(scope, jsii.String(id),
+		&customresources.AwsCustomResourceProps{
+			OnCreate: sdkCall,
+			OnUpdate: sdkCall,
+			Policy: customresources.AwsCustomResourcePolicy_FromStatements(&[]awsiam.PolicyStatement{
+				awsiam.NewPolicyStatement(&awsiam.PolicyStatementProps{
+					Actions:   jsii.Strings("sts:AssumeRole"),
+					Resources: &[]*string{roleARN},
+				}),
+			}),
+		})
+	return lookup.GetResponseField(jsii.String("Parameter.Value"))
+}
@@ -0,0 +1,95 @@
+package bwcdkutil
+
+// accounts.go maps deployments to AWS accounts, for apps that spread their
+// deployments over several accounts (e.g., production in its own account and
+// dev slots in a shared sandbox account):
+//
+//	"myapp-deployment-accounts": {"Prod": "111111111111", "Dev*": "222222222222"}
+//
+// Keys are deployment names or path.Match patterns. Without the key, every
+// stack uses CDK_DEFAULT_ACCOUNT as before.
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+)
+
+var accountIDRe = regexp.MustCompile(`^[0-9]{12}$`)
+
+// DeploymentAccount returns the account that deployment maps to. An exact
+// deployment name wins over patterns, and a longer pattern over a shorter
+// one. It returns "" if no key matches.
+func DeploymentAccount(accounts map[string]string, deployment string) string {
+	if account, ok := accounts[deployment]; ok {
+		return account
+	}
+	best := ""
+	for pattern := range accounts {
+		if ok, _ := path.Match(pattern, deployment); !ok {
+			continue
+		}
+		if len(pattern) > len(best) || len(pattern) == len(best) && pattern < best {
+			best = pattern
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return accounts[best]
+}
+
+// AccountFor returns the AWS account of a deployment, or "" when the app
+// does not map deployments to accounts and CDK_DEFAULT_ACCOUNT applies.
+func (c *Config) AccountFor(deployment string) string {
+	return DeploymentAccount(c.DeploymentAccounts, deployment)
+}
+
+// Accounts returns the distinct accounts of all deployments, in deployment
+// order. It returns a single "" when the app does not map deployments to
+// accounts.
+func (c *Config) Accounts() []string {
+	if len(c.DeploymentAccounts) == 0 {
+		return []string{""}
+	}
+	var accounts []string
+	for _, d := range c.Deployments {
+		if account := c.AccountFor(d); !slices.Contains(accounts, account) {
+			accounts = append(accounts, account)
+		}
+	}
+	return accounts
+}
+
+// IsMultiAccount reports whether the deployments span more than one account.
+func (c *Config) IsMultiAccount() bool {
+	return len(c.Accounts()) > 1
+}
+
+func validateDeploymentAccounts(accounts map[string]string, deployments []string, key string, errs []string) []string {
+	if len(accounts) == 0 {
+		return errs
+	}
+	patterns := make([]string, 0, len(accounts))
+	for pattern := range accounts {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = append(errs, fmt.Sprintf("context key %q: invalid deployment pattern %q", key, pattern))
+		}
+		if !accountIDRe.MatchString(accounts[pattern]) {
+			errs = append(errs, fmt.Sprintf("context key %q: account for %q must be a 12-digit ID, got %q",
+				key, pattern, accounts[pattern]))
+		}
+	}
+	for _, d := range deployments {
+		if DeploymentAccount(accounts, d) == "" {
+			errs = append(errs, fmt.Sprintf("context key %q has no account for deployment %q", key, d))
+		}
+	}
+	return errs
+}
@@ -34,6 +34,10 @@ type AppConfig struct {
 //  3. Deployment stacks for each allowed deployment in the primary region
 //  4. Secondary deployment stacks for each secondary region (dependent on primary deployment)
 //
+// When {prefix}deployment-accounts maps deployments to several accounts,
+// steps 1 and 2 repeat for every account, and deployment stacks land in the
+// account of their deployment, depending on that account's shared stacks.
+//
 // The type parameter S represents the shared construct type returned by SharedConstructor.
 // SetupApp validates all context values upfront and panics with a clear error message
 // if any required values are missing or invalid.
@@ -50,19 +54,23 @@ func SetupApp[S any](
 	}
 	StoreConfig(app, config)
 
-	// Create shared primary region stack first.
-	primarySharedStack := NewStackFromConfig(app, config, config.PrimaryRegion)
-	_ = newShared(primarySharedStack)
+	// Every account gets its own shared stacks: the primary region first, then
+	// the secondary regions. Secondary shared stacks reference resources (like
+	// Route53 hosted zone IDs) stored by the primary shared stack of their
+	// account, so they must deploy after it.
+	sharedStacks := make(map[string][]awscdk.Stack)
+	for _, account := range config.Accounts() {
+		primarySharedStack := NewSharedStackFromConfig(app, config, account, config.PrimaryRegion)
+		_ = newShared(primarySharedStack)
+		stacks := []awscdk.Stack{primarySharedStack}
 
-	// Create secondary shared region stacks with dependency on primary.
-	// Secondary shared stacks reference resources (like Route53 hosted zone IDs)
-	// stored by the primary shared stack, so they must deploy after it.
-	secondarySharedStacks := make([]awscdk.Stack, 0, len(config.SecondaryRegions))
-	for _, region := range config.SecondaryRegions {
-		secondarySharedStack := NewStackFromConfig(app, config, region)
-		_ = newShared(secondarySharedStack)
-		secondarySharedStack.AddDependency(primarySharedStack, jsii.String("Primary region must deploy first"))
-		secondarySharedStacks = append(secondarySharedStacks, secondarySharedStack)
+		for _, region := range config.SecondaryRegions {
+			secondarySharedStack := NewSharedStackFromConfig(app, config, account, region)
+			_ = newShared(secondarySharedStack)
+			secondarySharedStack.AddDependency(primarySharedStack, jsii.String("Primary region must deploy first"))
+			stacks = append(stacks, secondarySharedStack)
+		}
+		sharedStacks[account] = stacks
 	}
 
 	// Create stacks for each deployment.
@@ -70,17 +78,16 @@ func SetupApp[S any](
 		primaryDeploymentStack := NewStackFromConfig(app, config, config.PrimaryRegion, deploymentIdent)
 		newDeployment(primaryDeploymentStack, deploymentIdent)
 
-		// The primary deployment stack depends on ALL shared stacks (primary and secondary).
-		// This ensures all shared infrastructure is fully provisioned across all regions
-		// before any deployment begins. This creates a clean two-phase deployment:
+		// The primary deployment stack depends on ALL shared stacks (primary and
+		// secondary) of its account. This ensures all shared infrastructure is
+		// fully provisioned across all regions before any deployment begins.
+		// This creates a clean two-phase deployment:
 		//   Phase 1: All shared stacks (primary → secondary)
 		//   Phase 2: All deployment stacks (primary → secondary)
 		// This simplifies reasoning about deployment order and ensures secondary deployments
 		// can reference resources from their regional shared stacks.
-		primaryDeploymentStack.AddDependency(primarySharedStack,
-			jsii.String("All shared stacks must deploy before deployments"))
-		for _, secondarySharedStack := range secondarySharedStacks {
-			primaryDeploymentStack.AddDependency(secondarySharedStack,
+		for _, sharedStack := range sharedStacks[config.AccountFor(deploymentIdent)] {
+			primaryDeploymentStack.AddDependency(sharedStack,
 				jsii.String("All shared stacks must deploy before deployments"))
 		}
 
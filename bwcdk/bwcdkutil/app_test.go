@@ -1,6 +1,7 @@
 package bwcdkutil_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/aws/aws-cdk-go/awscdk/v2"
@@ -128,3 +129,58 @@ func TestSetupApp_WithSecondaryRegions(t *testing.T) {
 		}
 	}
 }
+
+func TestSetupApp_MultiAccount(t *testing.T) {
+	defer jsii.Close()
+
+	ctx := map[string]any{
+		"myapp-qualifier":         "myapp",
+		"myapp-primary-region":    "us-east-1",
+		"myapp-secondary-regions": []any{"eu-west-1"},
+		"myapp-deployments":       []any{"Prod", "Dev01"},
+		"myapp-base-domain-name":  "example.com",
+		"myapp-deployment-accounts": map[string]any{
+			"Prod": "111111111111",
+			"Dev*": "222222222222",
+		},
+	}
+
+	app := awscdk.NewApp(&awscdk.AppProps{
+		Context: &ctx,
+	})
+
+	var sharedCalls []string
+	bwcdkutil.SetupApp(app, bwcdkutil.AppConfig{
+		Prefix: "myapp-",
+	},
+		func(stack awscdk.Stack) *testShared {
+			sharedCalls = append(sharedCalls, *stack.Account()+"/"+*stack.Region())
+			return &testShared{Region: *stack.Region()}
+		},
+		func(awscdk.Stack, string) {},
+	)
+
+	wantShared := []string{
+		"111111111111/us-east-1", "111111111111/eu-west-1",
+		"222222222222/us-east-1", "222222222222/eu-west-1",
+	}
+	if strings.Join(sharedCalls, " ") != strings.Join(wantShared, " ") {
+		t.Errorf("shared calls = %v, want %v", sharedCalls, wantShared)
+	}
+
+	dev := app.Node().FindChild(jsii.String("myappUse1Dev01")).(awscdk.Stack)
+	if got := *dev.Account(); got != "222222222222" {
+		t.Errorf("Dev01 account = %q, want 222222222222", got)
+	}
+	var deps []string
+	for _, dep := range *dev.Dependencies() {
+		deps = append(deps, *dep.Node().Id()+"@"+*dep.StackName())
+	}
+	wantDeps := []string{
+		"myappUse1Shared222222222222@myappUse1Shared",
+		"myappEuw1Shared222222222222@myappEuw1Shared",
+	}
+	if strings.Join(deps, " ") != strings.Join(wantDeps, " ") {
+		t.Errorf("Dev01 dependencies = %v, want %v", deps, wantDeps)
+	}
+}
@@ -111,6 +111,10 @@ type Config struct {
 	// Validation flags for foundational infrastructure
 	DNSDelegated bool // true when DNS delegation is complete
 
+	// DeploymentAccounts maps deployment names or patterns to AWS account IDs.
+	// Empty when every stack uses CDK_DEFAULT_ACCOUNT. See accounts.go.
+	DeploymentAccounts map[string]string
+
 	// legacyRegionIdents holds custom region identifiers read from CDK context.
 	// Only populated when AppConfig.LegacyRegionIdent is true.
 	legacyRegionIdents map[string]string
@@ -119,18 +123,40 @@ type Config struct {
 // NewConfig reads and validates all CDK context values.
 // Returns an error if any required value is missing or invalid.
 func NewConfig(scope constructs.Construct, acfg AppConfig) (*Config, error) {
+	return newConfig(func(key string) any {
+		return scope.Node().TryGetContext(jsii.String(key))
+	}, acfg)
+}
+
+// LoadConfig reads and validates CDK context values from a plain map, as
+// decoded from cdk.json and cdk.context.json. It applies the same rules as
+// NewConfig without a construct tree, so tools can report context errors
+// without starting jsii.
+func LoadConfig(values map[string]any, acfg AppConfig) (*Config, error) {
+	return newConfig(func(key string) any {
+		return values[key]
+	}, acfg)
+}
+
+// contextLookup returns the context value for a key, or nil if it is not set.
+type contextLookup func(key string) any
+
+func newConfig(lookup contextLookup, acfg AppConfig) (*Config, error) {
 	var readErrs []string
 
 	cfg := &Config{
 		Prefix: acfg.Prefix,
 	}
 
-	cfg.Qualifier, readErrs = readContextString(scope, acfg.Prefix+"qualifier", readErrs)
-	cfg.PrimaryRegion, readErrs = readContextString(scope, acfg.Prefix+"primary-region", readErrs)
-	cfg.SecondaryRegions, readErrs = readContextStringSlice(scope, acfg.Prefix+"secondary-regions", readErrs)
-	cfg.Deployments, readErrs = readContextStringSlice(scope, acfg.Prefix+"deployments", readErrs)
-	cfg.BaseDomainName, readErrs = readContextString(scope, acfg.Prefix+"base-domain-name", readErrs)
-	cfg.DNSDelegated = readOptionalContextBool(scope, acfg.Prefix+"dns-delegated")
+	cfg.Qualifier, readErrs = readContextString(lookup, acfg.Prefix+"qualifier", readErrs)
+	cfg.PrimaryRegion, readErrs = readContextString(lookup, acfg.Prefix+"primary-region", readErrs)
+	cfg.SecondaryRegions, readErrs = readContextStringSlice(lookup, acfg.Prefix+"secondary-regions", readErrs)
+	cfg.Deployments, readErrs = readContextStringSlice(lookup, acfg.Prefix+"deployments", readErrs)
+	cfg.BaseDomainName, readErrs = readContextString(lookup, acfg.Prefix+"base-domain-name", readErrs)
+	cfg.DNSDelegated = readOptionalContextBool(lookup, acfg.Prefix+"dns-delegated")
+	cfg.DeploymentAccounts, readErrs = readOptionalContextStringMap(lookup, acfg.Prefix+"deployment-accounts", readErrs)
+	readErrs = validateDeploymentAccounts(cfg.DeploymentAccounts, cfg.Deployments,
+		acfg.Prefix+"deployment-accounts", readErrs)
 
 	if acfg.LegacyRegionIdent {
 		// Legacy mode: read custom region identifiers from CDK context keys
@@ -141,7 +167,7 @@ func NewConfig(scope constructs.Construct, acfg AppConfig) (*Config, error) {
 			allRegions = append(allRegions, cfg.PrimaryRegion)
 		}
 		allRegions = append(allRegions, cfg.SecondaryRegions...)
-		cfg.legacyRegionIdents, readErrs = readLegacyRegionIdents(scope, acfg.Prefix, allRegions, readErrs)
+		cfg.legacyRegionIdents, readErrs = readLegacyRegionIdents(lookup, acfg.Prefix, allRegions, readErrs)
 	} else {
 		// Standard mode: validate that all regions are known in the hardcoded map.
 		if cfg.PrimaryRegion != "" && !IsKnownRegion(cfg.PrimaryRegion) {
@@ -156,6 +182,12 @@ func NewConfig(scope constructs.Construct, acfg AppConfig) (*Config, error) {
 		}
 	}
 
+	// A region listed twice would create two stacks with the same name.
+	if cfg.PrimaryRegion != "" && slices.Contains(cfg.SecondaryRegions, cfg.PrimaryRegion) {
+		readErrs = append(readErrs, fmt.Sprintf(
+			"primary region %q is also listed as a secondary region", cfg.PrimaryRegion))
+	}
+
 	// Validate that at least one deployment is a prod deployment
 	hasProd := slices.ContainsFunc(cfg.Deployments, IsProdDeployment)
 	if !hasProd {
@@ -253,8 +285,8 @@ func formatValidationError(e validator.FieldError) string {
 	}
 }
 
-func readContextString(scope constructs.Construct, key string, errs []string) (string, []string) {
-	val := scope.Node().TryGetContext(jsii.String(key))
+func readContextString(lookup contextLookup, key string, errs []string) (string, []string) {
+	val := lookup(key)
 	if val == nil {
 		return "", append(errs, fmt.Sprintf("context key %q is not set", key))
 	}
@@ -265,8 +297,8 @@ func readContextString(scope constructs.Construct, key string, errs []string) (s
 	return s, errs
 }
 
-func readContextStringSlice(scope constructs.Construct, key string, errs []string) ([]string, []string) {
-	val := scope.Node().TryGetContext(jsii.String(key))
+func readContextStringSlice(lookup contextLookup, key string, errs []string) ([]string, []string) {
+	val := lookup(key)
 	if val == nil {
 		return nil, append(errs, fmt.Sprintf("context key %q is not set", key))
 	}
@@ -287,8 +319,8 @@ func readContextStringSlice(scope constructs.Construct, key string, errs []strin
 	return result, errs
 }
 
-func readOptionalContextBool(scope constructs.Construct, key string) bool {
-	val := scope.Node().TryGetContext(jsii.String(key))
+func readOptionalContextBool(lookup contextLookup, key string) bool {
+	val := lookup(key)
 	if val == nil {
 		return false
 	}
@@ -298,3 +330,24 @@ func readOptionalContextBool(scope constructs.Construct, key string) bool {
 	}
 	return b
 }
+
+func readOptionalContextStringMap(lookup contextLookup, key string, errs []string) (map[string]string, []string) {
+	val := lookup(key)
+	if val == nil {
+		return nil, errs
+	}
+	m, ok := val.(map[string]any)
+	if !ok {
+		return nil, append(errs, fmt.Sprintf("context key %q must be an object, got %T", key, val))
+	}
+
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		s, ok := v.(string)
+		if !ok {
+			return nil, append(errs, fmt.Sprintf("context key %q[%q] must be a string, got %T", key, k, v))
+		}
+		result[k] = s
+	}
+	return result, errs
+}
@@ -261,3 +261,134 @@ func TestConfig_RegionIdent(t *testing.T) {
 		t.Errorf("RegionIdent(eu-west-1) = %q, want %q", ident, "Euw1")
 	}
 }
+
+func TestLoadConfig(t *testing.T) {
+	valid := func() map[string]any {
+		return map[string]any{
+			"myapp-qualifier":         "myapp",
+			"myapp-primary-region":    "us-east-1",
+			"myapp-secondary-regions": []any{"eu-west-1"},
+			"myapp-deployments":       []any{"Dev", "Prod"},
+			"myapp-base-domain-name":  "example.com",
+		}
+	}
+
+	tests := []struct {
+		name        string
+		modify      func(values map[string]any)
+		appConfig   bwcdkutil.AppConfig
+		errContains []string
+	}{
+		{
+			name:   "valid config",
+			modify: func(map[string]any) {},
+		},
+		{
+			name: "collects every error",
+			modify: func(values map[string]any) {
+				delete(values, "myapp-qualifier")
+				values["myapp-secondary-regions"] = []any{"mars-north-1"}
+				values["myapp-deployments"] = []any{"Dev"}
+			},
+			errContains: []string{
+				`"myapp-qualifier" is not set`,
+				`unknown secondary region "mars-north-1"`,
+				`must be named "Prod"`,
+			},
+		},
+		{
+			name: "primary region repeated as secondary",
+			modify: func(values map[string]any) {
+				values["myapp-secondary-regions"] = []any{"us-east-1"}
+			},
+			errContains: []string{`primary region "us-east-1" is also listed as a secondary region`},
+		},
+		{
+			name: "invalid base domain",
+			modify: func(values map[string]any) {
+				values["myapp-base-domain-name"] = "not a domain"
+			},
+			errContains: []string{"BaseDomainName must be a valid domain name"},
+		},
+		{
+			name: "deployment accounts",
+			modify: func(values map[string]any) {
+				values["myapp-deployment-accounts"] = map[string]any{
+					"Prod": "111111111111",
+					"D*":   "12345",
+					"[":    "222222222222",
+				}
+			},
+			errContains: []string{
+				`account for "D*" must be a 12-digit ID`,
+				`invalid deployment pattern "["`,
+			},
+		},
+		{
+			name: "deployment without account",
+			modify: func(values map[string]any) {
+				values["myapp-deployment-accounts"] = map[string]any{"Prod": "111111111111"}
+			},
+			errContains: []string{`has no account for deployment "Dev"`},
+		},
+		{
+			name: "legacy region ident missing",
+			modify: func(values map[string]any) {
+				values["myapp-region-ident-us-east-1"] = "Ue"
+			},
+			appConfig:   bwcdkutil.AppConfig{LegacyRegionIdent: true},
+			errContains: []string{`"myapp-region-ident-eu-west-1" is not set`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := valid()
+			tt.modify(values)
+			acfg := tt.appConfig
+			acfg.Prefix = "myapp-"
+
+			cfg, err := bwcdkutil.LoadConfig(values, acfg)
+			if len(tt.errContains) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if cfg.RegionIdent("eu-west-1") != "Euw1" {
+					t.Errorf("RegionIdent(eu-west-1) = %q, want Euw1", cfg.RegionIdent("eu-west-1"))
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected error but got nil")
+			}
+			for _, contains := range tt.errContains {
+				if !strings.Contains(err.Error(), contains) {
+					t.Errorf("error %q should contain %q", err.Error(), contains)
+				}
+			}
+		})
+	}
+}
+
+func TestDeploymentAccount(t *testing.T) {
+	accounts := map[string]string{
+		"Prod":  "111111111111",
+		"Dev*":  "222222222222",
+		"Dev1*": "333333333333",
+		"*":     "444444444444",
+	}
+	tests := map[string]string{
+		"Prod":  "111111111111",
+		"Dev02": "222222222222",
+		"Dev12": "333333333333",
+		"Stag":  "444444444444",
+	}
+	for deployment, want := range tests {
+		if got := bwcdkutil.DeploymentAccount(accounts, deployment); got != want {
+			t.Errorf("DeploymentAccount(%q) = %q, want %q", deployment, got, want)
+		}
+	}
+	if got := bwcdkutil.DeploymentAccount(nil, "Prod"); got != "" {
+		t.Errorf("DeploymentAccount without accounts = %q, want empty", got)
+	}
+}
@@ -42,6 +42,7 @@
 // # Features
 //
 //   - [SetupApp]: Multi-region, multi-deployment app orchestration
+//   - [LoadConfig]: Context validation without a construct tree, for tooling
 //   - [NewStack]: Stack creation with qualifier and region naming
 //   - [ReproducibleGoBundling]: Lambda bundling for identical builds
 //   - [PreserveExport]: CloudFormation export preservation
@@ -12,22 +12,18 @@ package bwcdkutil
 // "{prefix}region-ident-{region}" (e.g., "bw-region-ident-eu-central-1": "De")
 // in cdk.context.json.
 
-import (
-	"fmt"
-
-	"github.com/aws/constructs-go/constructs/v10"
-)
+import "fmt"
 
 // readLegacyRegionIdents reads custom region identifiers from CDK context.
 // For each region in regions, it looks up the key "{prefix}region-ident-{region}".
 // Returns a map[string]string of region → custom ident, and any errors.
 func readLegacyRegionIdents(
-	scope constructs.Construct, prefix string, regions []string, errs []string,
+	lookup contextLookup, prefix string, regions []string, errs []string,
 ) (map[string]string, []string) {
 	idents := make(map[string]string, len(regions))
 	for _, region := range regions {
 		key := fmt.Sprintf("%sregion-ident-%s", prefix, region)
-		val, newErrs := readContextString(scope, key, errs)
+		val, newErrs := readContextString(lookup, key, errs)
 		errs = newErrs
 		if val != "" {
 			idents[region] = val
@@ -1,7 +1,6 @@
 package bwcdkutil
 
 import (
-	"regexp"
 	"slices"
 	"strings"
 )
@@ -63,8 +62,6 @@ func init() {
 	}
 }
 
-var regionIdentRe = regexp.MustCompile(`^bwapp([A-Z][a-z]+[0-9])`)
-
 // RegionForIdent returns the AWS region code for a 4-character identifier.
 // Returns the region and true if found, or empty string and false if unknown.
 func RegionForIdent(ident string) (string, bool) {
@@ -72,17 +69,6 @@ func RegionForIdent(ident string) (string, bool) {
 	return region, ok
 }
 
-// ExtractRegionIdent extracts the 4-character region identifier from a CDK stack name.
-// Stack names follow the pattern "bwapp{Ident}..." (e.g., "bwappEuw1Stag" → "Euw1").
-// Returns empty string if the pattern does not match.
-func ExtractRegionIdent(stackName string) string {
-	m := regionIdentRe.FindStringSubmatch(stackName)
-	if len(m) < 2 {
-		return ""
-	}
-	return m[1]
-}
-
 // RegionIdentFor returns the 4-character identifier for an AWS region.
 // It panics if the region is unknown. Use IsKnownRegion to check first if needed.
 func RegionIdentFor(region string) string {
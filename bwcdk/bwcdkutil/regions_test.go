@@ -26,21 +26,50 @@ func TestRegionForIdent_Unknown(t *testing.T) {
 	}
 }
 
-func TestExtractRegionIdent(t *testing.T) {
+func TestParseStackName(t *testing.T) {
 	tests := []struct {
-		stackName string
-		want      string
+		qualifier string
+		name      string
+		legacy    map[string]string
+		want      bwcdkutil.StackName
+		wantOK    bool
 	}{
-		{"bwappEuw1Stag", "Euw1"},
-		{"bwappUse1Prod", "Use1"},
-		{"bwappEuc2Shared", "Euc2"},
-		{"notastack", ""},
-		{"", ""},
+		{"bwapp", "bwappEuw1Stag", nil, bwcdkutil.StackName{
+			Region: "eu-west-1", RegionIdent: "Euw1", Deployment: "Stag"}, true},
+		{"bwapp", "bwappEuc1Shared", nil, bwcdkutil.StackName{
+			Region: "eu-central-1", RegionIdent: "Euc1", Shared: true}, true},
+		{"acme", "acmeUse1Dev01", nil, bwcdkutil.StackName{
+			Region: "us-east-1", RegionIdent: "Use1", Deployment: "Dev01"}, true},
+		{"bcx", "bcxDeProd", map[string]string{"eu-central-1": "De"}, bwcdkutil.StackName{
+			Region: "eu-central-1", RegionIdent: "De", Deployment: "Prod"}, true},
+		{"bcx", "bcxEuw1Shared", map[string]string{"eu-central-1": "De"}, bwcdkutil.StackName{
+			Region: "eu-west-1", RegionIdent: "Euw1", Shared: true}, true},
+		{"bwapp", "acmeEuw1Stag", nil, bwcdkutil.StackName{}, false},
+		{"bwapp", "bwappZzz9Stag", nil, bwcdkutil.StackName{}, false},
+		{"bwapp", "bwappEuw1", nil, bwcdkutil.StackName{}, false},
+		{"bwapp", "bwappEuw1stag", nil, bwcdkutil.StackName{}, false},
 	}
 	for _, tt := range tests {
-		got := bwcdkutil.ExtractRegionIdent(tt.stackName)
-		if got != tt.want {
-			t.Errorf("ExtractRegionIdent(%q) = %q, want %q", tt.stackName, got, tt.want)
+		got, ok := bwcdkutil.ParseLegacyStackName(tt.qualifier, tt.name, tt.legacy)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("ParseLegacyStackName(%q, %q) = %+v, %v; want %+v, %v",
+				tt.qualifier, tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestParseStackName_RoundTrip(t *testing.T) {
+	for _, qualifier := range []string{"bwapp", "acme", "my-app"} {
+		for region, ident := range bwcdkutil.RegionIdents {
+			shared, ok := bwcdkutil.ParseStackName(qualifier, bwcdkutil.SharedStackName(qualifier, ident))
+			if !ok || !shared.Shared || shared.Region != region {
+				t.Errorf("%s shared stack in %s: got %+v, %v", qualifier, region, shared, ok)
+			}
+			name := bwcdkutil.DeploymentStackName(qualifier, ident, "Dev01")
+			deployment, ok := bwcdkutil.ParseStackName(qualifier, name)
+			if !ok || deployment.Shared || deployment.Region != region || deployment.Deployment != "Dev01" {
+				t.Errorf("%s: got %+v, %v", name, deployment, ok)
+			}
 		}
 	}
 }
@@ -3,7 +3,9 @@ package bwcdkutil
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strings"
+	"unicode"
 
 	"github.com/aws/aws-cdk-go/awscdk/v2"
 	"github.com/aws/constructs-go/constructs/v10"
@@ -15,7 +17,7 @@ import (
 // This is the canonical function for generating shared stack names.
 func SharedStackName(qualifier, regionIdent string) string {
 	base := strcase.ToLowerCamel(fmt.Sprintf("%s-%s", qualifier, regionIdent))
-	return base + "Shared"
+	return base + sharedStackSuffix
 }
 
 // DeploymentStackName returns the CloudFormation stack name for a deployment stack.
@@ -25,6 +27,64 @@ func DeploymentStackName(qualifier, regionIdent, deploymentIdent string) string
 	return base + deploymentIdent
 }
 
+// sharedStackSuffix ends the name of every shared stack.
+const sharedStackSuffix = "Shared"
+
+// StackName is a stack name taken apart by ParseStackName.
+type StackName struct {
+	Region      string
+	RegionIdent string
+	// Deployment is empty for shared stacks.
+	Deployment string
+	Shared     bool
+}
+
+// ParseStackName takes apart a stack name built by SharedStackName or
+// DeploymentStackName for the given qualifier, using the standard region
+// identifiers. It returns false if the name does not belong to the qualifier
+// or has no known region identifier.
+func ParseStackName(qualifier, name string) (StackName, bool) {
+	return parseStackName(qualifier, name, nil)
+}
+
+// ParseLegacyStackName takes apart a stack name like ParseStackName, but
+// also recognizes legacy region identifiers, given as region to identifier.
+// Legacy identifiers take precedence over the standard ones.
+func ParseLegacyStackName(qualifier, name string, legacyRegionIdents map[string]string) (StackName, bool) {
+	return parseStackName(qualifier, name, legacyRegionIdents)
+}
+
+func parseStackName(qualifier, name string, legacyRegionIdents map[string]string) (StackName, bool) {
+	candidates := make([]StackName, 0, len(RegionIdents)+len(legacyRegionIdents))
+	for region, ident := range legacyRegionIdents {
+		candidates = append(candidates, StackName{Region: region, RegionIdent: ident})
+	}
+	for region, ident := range RegionIdents {
+		if _, legacy := legacyRegionIdents[region]; !legacy {
+			candidates = append(candidates, StackName{Region: region, RegionIdent: ident})
+		}
+	}
+	// The longest identifier wins, so a legacy "De" never shadows "Dev1".
+	slices.SortStableFunc(candidates, func(a, b StackName) int {
+		return len(b.RegionIdent) - len(a.RegionIdent)
+	})
+
+	for _, sn := range candidates {
+		rest, ok := strings.CutPrefix(name, strcase.ToLowerCamel(fmt.Sprintf("%s-%s", qualifier, sn.RegionIdent)))
+		switch {
+		case !ok || rest == "":
+			continue
+		case rest == sharedStackSuffix:
+			sn.Shared = true
+			return sn, true
+		case unicode.IsUpper(rune(rest[0])):
+			sn.Deployment = rest
+			return sn, true
+		}
+	}
+	return StackName{}, false
+}
+
 // NewStack creates a new CDK Stack, either shared or multi-deployment.
 //
 // Deprecated: Use NewStackFromConfig instead for upfront validation.
@@ -33,18 +93,40 @@ func NewStack(
 ) awscdk.Stack {
 	qual := QualifierFromContext(scope, prefix)
 	regionAcronym := RegionAcronymIdentFromContext(scope, prefix, region)
-	return newStackInternal(scope, qual, regionAcronym, region, deploymentIdent...)
+	return newStackInternal(scope, "", qual, regionAcronym, region, "", deploymentIdent...)
 }
 
 // NewStackFromConfig creates a new CDK Stack using a validated Config.
+// Deployment stacks are placed in the account of their deployment; shared
+// stacks use CDK_DEFAULT_ACCOUNT. Use NewSharedStackFromConfig to place a
+// shared stack in a specific account.
 func NewStackFromConfig(
 	scope constructs.Construct, cfg *Config, region string, deploymentIdent ...string,
 ) awscdk.Stack {
-	return newStackInternal(scope, cfg.Qualifier, cfg.RegionIdent(region), region, deploymentIdent...)
+	account := ""
+	if len(deploymentIdent) > 0 {
+		account = cfg.AccountFor(deploymentIdent[0])
+	}
+	return newStackInternal(scope, "", cfg.Qualifier, cfg.RegionIdent(region), region, account, deploymentIdent...)
 }
 
+// NewSharedStackFromConfig creates the shared stack of an account and region.
+// Every account gets its own shared stacks with the same CloudFormation name,
+// so in a multi-account app the construct ID carries the account to keep
+// them apart in the cloud assembly.
+func NewSharedStackFromConfig(scope constructs.Construct, cfg *Config, account, region string) awscdk.Stack {
+	id := ""
+	if cfg.IsMultiAccount() {
+		id = SharedStackName(cfg.Qualifier, cfg.RegionIdent(region)) + account
+	}
+	return newStackInternal(scope, id, cfg.Qualifier, cfg.RegionIdent(region), region, account)
+}
+
+// newStackInternal creates a stack named after the qualifier, region and
+// deployment. The construct ID defaults to the stack name. An empty account
+// falls back to CDK_DEFAULT_ACCOUNT.
 func newStackInternal(
-	scope constructs.Construct, qual, regionAcronym, region string, deploymentIdent ...string,
+	scope constructs.Construct, id, qual, regionAcronym, region, account string, deploymentIdent ...string,
 ) awscdk.Stack {
 	var stackName string
 	var description string
@@ -67,9 +149,17 @@ func newStackInternal(
 		description = fmt.Sprintf("%s (region: %s)", baseIdent, region)
 	}
 
-	stack := awscdk.NewStack(scope, jsii.String(stackName), &awscdk.StackProps{
+	if id == "" {
+		id = stackName
+	}
+	if account == "" {
+		account = os.Getenv("CDK_DEFAULT_ACCOUNT")
+	}
+
+	stack := awscdk.NewStack(scope, jsii.String(id), &awscdk.StackProps{
+		StackName: jsii.String(stackName),
 		Env: &awscdk.Environment{
-			Account: jsii.String(os.Getenv("CDK_DEFAULT_ACCOUNT")),
+			Account: jsii.String(account),
 			Region:  jsii.String(region),
 		},
 		Description: jsii.String(description),
@@ -12,6 +12,7 @@ type InfraBootstrapCmd struct {
 	Profile             string `help:"AWS profile to use for bootstrap (requires admin permissions)."`
 	ExecutionPolicies   string `name:"execution-policies" help:"IAM policy ARNs for CFN execution role."`
 	PermissionsBoundary string `name:"permissions-boundary" help:"IAM permissions boundary for bootstrap roles."`
+	ShowTemplate        bool   `name:"show-template" help:"Print the patched bootstrap template instead of deploying it."`
 }
 
 func (c *InfraBootstrapCmd) Run(cfg *wscfg.Config, reg *tool.Registry) error {
@@ -20,6 +21,7 @@ func (c *InfraBootstrapCmd) Run(cfg *wscfg.Config, reg *tool.Registry) error {
 		Profile:             c.Profile,
 		ExecutionPolicies:   c.ExecutionPolicies,
 		PermissionsBoundary: c.PermissionsBoundary,
+		ShowTemplate:        c.ShowTemplate,
 	})
 	g, err := dag.Build(cfg.Projects, reg, cfg, []tool.Step{tool.StepBootstrap})
 	if err != nil {
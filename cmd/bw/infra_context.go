@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/basewarphq/bw/cmd/internal/cdkctx"
+	"github.com/basewarphq/bw/cmd/internal/tool/cdktool"
+	"github.com/basewarphq/bw/cmd/internal/wscfg"
+	"github.com/cockroachdb/errors"
+)
+
+type InfraContextCmd struct {
+	Get              InfraContextGetCmd              `cmd:"" help:"Print a cdk.context.json value."`
+	Set              InfraContextSetCmd              `cmd:"" help:"Set a cdk.context.json value."`
+	AddDeployment    InfraContextAddDeploymentCmd    `cmd:"" help:"Add a deployment."`
+	RemoveDeployment InfraContextRemoveDeploymentCmd `cmd:"" help:"Remove a deployment that has no deployed stacks."`
+	AddRegion        InfraContextAddRegionCmd        `cmd:"" help:"Add a secondary region."`
+}
+
+func infraContextDir(cfg *wscfg.Config) (string, error) {
+	proj, err := cfg.FindProjectByTool("cdk")
+	if err != nil {
+		return "", err
+	}
+	return cdktool.ContextDir(cfg.ProjectToolConfig(proj.Name, "cdk"), cfg.ProjectDir(*proj)), nil
+}
+
+type InfraContextGetCmd struct {
+	Key string `arg:"" help:"Context key; the qualifier prefix is added when missing (e.g., deployments)."`
+}
+
+func (c *InfraContextGetCmd) Run(cfg *wscfg.Config) error {
+	dir, err := infraContextDir(cfg)
+	if err != nil {
+		return err
+	}
+	value, err := cdkctx.Get(dir, c.Key)
+	if err != nil {
+		return err
+	}
+
+	// Strings print bare so the output can be used in scripts.
+	var s string
+	if json.Unmarshal(value, &s) == nil {
+		fmt.Fprintln(os.Stdout, s)
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, value, "", "  "); err != nil {
+		return errors.Wrapf(err, "formatting %s", c.Key)
+	}
+	fmt.Fprintln(os.Stdout, buf.String())
+	return nil
+}
+
+type InfraContextSetCmd struct {
+	Key   string `arg:"" help:"Context key; the qualifier prefix is added when missing (e.g., base-domain-name)."`
+	Value string `arg:"" help:"New value. Valid JSON is stored as JSON, anything else as a string."`
+}
+
+func (c *InfraContextSetCmd) Run(cfg *wscfg.Config) error {
+	dir, err := infraContextDir(cfg)
+	if err != nil {
+		return err
+	}
+	var value any = c.Value
+	if json.Valid([]byte(c.Value)) {
+		value = json.RawMessage(c.Value)
+	}
+	return cdkctx.Set(dir, c.Key, value)
+}
+
+type InfraContextAddDeploymentCmd struct {
+	Name string `arg:"" help:"Deployment name (e.g., Stag, Dev03)."`
+}
+
+func (c *InfraContextAddDeploymentCmd) Run(cfg *wscfg.Config) error {
+	dir, err := infraContextDir(cfg)
+	if err != nil {
+		return err
+	}
+	if err := cdkctx.AddDeployment(dir, c.Name); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Added deployment %s\n", c.Name)
+	return nil
+}
+
+type InfraContextRemoveDeploymentCmd struct {
+	Name string `arg:"" help:"Deployment name."`
+}
+
+func (c *InfraContextRemoveDeploymentCmd) Run(cfg *wscfg.Config) error {
+	proj, err := cfg.FindProjectByTool("cdk")
+	if err != nil {
+		return err
+	}
+	err = cdktool.RemoveDeployment(context.Background(),
+		cfg.ProjectToolConfig(proj.Name, "cdk"), cfg.ProjectDir(*proj), c.Name)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Removed deployment %s\n", c.Name)
+	return nil
+}
+
+type InfraContextAddRegionCmd struct {
+	Region string `arg:"" help:"AWS region (e.g., eu-west-1)."`
+}
+
+func (c *InfraContextAddRegionCmd) Run(cfg *wscfg.Config) error {
+	dir, err := infraContextDir(cfg)
+	if err != nil {
+		return err
+	}
+	if err := cdkctx.AddRegion(dir, c.Region); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Added region %s; bootstrap it with 'bw infra bootstrap' before deploying\n", c.Region)
+	return nil
+}
@@ -10,7 +10,11 @@ import (
 
 type InfraDeployCmd struct {
 	Deployment string `arg:"" optional:"" help:"Deployment name (e.g., Stag, Prod). Defaults to claimed dev slot."`
-	Hotswap    bool   `help:"Enable CDK hotswap deployment for faster iterations."`
+	Hotswap    bool   `help:"Enable CDK hotswap deployment for faster iterations." xor:"mode"`
+	Raw        bool   `help:"Stream raw cdk output instead of the stack progress table."`
+	Plan       bool   `help:"Create change sets without executing them and write them to --plan-file." xor:"mode"`
+	PlanFile   string `help:"Plan file written by --plan." default:"plan.json" type:"path"`
+	Apply      string `help:"Execute the change sets of a plan file written by --plan." xor:"mode" type:"existingfile"`
 }
 
 func (c *InfraDeployCmd) Run(cfg *wscfg.Config, reg *tool.Registry) error {
@@ -18,9 +22,11 @@ func (c *InfraDeployCmd) Run(cfg *wscfg.Config, reg *tool.Registry) error {
 	if c.Deployment != "" {
 		ctx = tool.WithDeployment(ctx, c.Deployment)
 	}
-	ctx = tool.WithDeployOptions(ctx, tool.DeployOptions{
-		Hotswap: c.Hotswap,
-	})
+	opts := tool.DeployOptions{Hotswap: c.Hotswap, Raw: c.Raw, ApplyFile: c.Apply}
+	if c.Plan {
+		opts.PlanFile = c.PlanFile
+	}
+	ctx = tool.WithDeployOptions(ctx, opts)
 	g, err := dag.Build(cfg.Projects, reg, cfg, []tool.Step{tool.StepDeploy})
 	if err != nil {
 		return err
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+
+	"github.com/basewarphq/bw/cmd/internal/dag"
+	"github.com/basewarphq/bw/cmd/internal/tool"
+	"github.com/basewarphq/bw/cmd/internal/wscfg"
+)
+
+type InfraDestroyCmd struct {
+	Deployment      string `arg:"" help:"Deployment name to destroy (e.g., Dev03)."`
+	Confirm         string `help:"Deployment name, to skip the interactive confirmation (for CI)."`
+	IKnowThisIsProd bool   `name:"i-know-this-is-prod" help:"Allow destroying Prod; also requires BW_I_KNOW_THIS_IS_PROD=<deployment>."`
+}
+
+func (c *InfraDestroyCmd) Run(cfg *wscfg.Config, reg *tool.Registry) error {
+	ctx := context.Background()
+	ctx = tool.WithDeployment(ctx, c.Deployment)
+	ctx = tool.WithDestroyOptions(ctx, tool.DestroyOptions{
+		Confirm:         c.Confirm,
+		IKnowThisIsProd: c.IKnowThisIsProd,
+	})
+	g, err := dag.Build(cfg.Projects, reg, cfg, []tool.Step{tool.StepDestroy})
+	if err != nil {
+		return err
+	}
+	return dag.Execute(ctx, g, cliReporter{})
+}
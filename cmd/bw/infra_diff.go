@@ -9,7 +9,10 @@ import (
 )
 
 type InfraDiffCmd struct {
-	Deployment string `arg:"" optional:"" help:"Deployment name (e.g., Stag, Prod). Defaults to claimed dev slot."`
+	Deployment        string `arg:"" optional:"" help:"Deployment name (e.g., Stag, Prod). Defaults to claimed dev slot."`
+	Raw               bool   `help:"Stream the raw cdk diff output instead of the summary."`
+	JSON              bool   `name:"json" help:"Print the summary as JSON."`
+	FailOnReplacement bool   `help:"Exit non-zero when any resource will be replaced."`
 }
 
 func (c *InfraDiffCmd) Run(cfg *wscfg.Config, reg *tool.Registry) error {
@@ -17,6 +20,11 @@ func (c *InfraDiffCmd) Run(cfg *wscfg.Config, reg *tool.Registry) error {
 	if c.Deployment != "" {
 		ctx = tool.WithDeployment(ctx, c.Deployment)
 	}
+	ctx = tool.WithDiffOptions(ctx, tool.DiffOptions{
+		Raw:               c.Raw,
+		JSON:              c.JSON,
+		FailOnReplacement: c.FailOnReplacement,
+	})
 	g, err := dag.Build(cfg.Projects, reg, cfg, []tool.Step{tool.StepDiff})
 	if err != nil {
 		return err
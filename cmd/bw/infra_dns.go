@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/basewarphq/bw/cmd/internal/tool/cdktool"
+	"github.com/basewarphq/bw/cmd/internal/wscfg"
+	"github.com/cockroachdb/errors"
+)
+
+type InfraDNSCmd struct {
+	Check InfraDNSCheckCmd `cmd:"" help:"Verify that the base domain is delegated to the hosted zone."`
+}
+
+type InfraDNSCheckCmd struct {
+	Yes bool `help:"Set the dns-delegated flag without asking when delegation is complete." short:"y"`
+}
+
+func (c *InfraDNSCheckCmd) Run(cfg *wscfg.Config) error {
+	ctx := context.Background()
+
+	proj, err := cfg.FindProjectByTool("cdk")
+	if err != nil {
+		return err
+	}
+	status, err := cdktool.CheckDNS(ctx, cfg.ProjectToolConfig(proj.Name, "cdk"), cfg.ProjectDir(*proj), &cliNodeReporter{})
+	if err != nil {
+		return err
+	}
+
+	if !status.Delegated {
+		return errors.New("DNS delegation is not complete; point the parent zone's NS records at the hosted zone")
+	}
+	if status.FlagSet {
+		return nil
+	}
+
+	if !c.Yes {
+		fmt.Fprintf(os.Stderr, "Set %s to true in cdk.context.json? [y/N] ", status.FlagKey)
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			return nil
+		}
+	}
+	if err := cdktool.MarkDNSDelegated(status); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Set %s to true; deploy the shared stack to create certificates.\n", status.FlagKey)
+	return nil
+}
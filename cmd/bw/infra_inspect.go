@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 
+	"github.com/basewarphq/bw/cmd/internal/cfnread"
 	"github.com/basewarphq/bw/cmd/internal/dag"
 	"github.com/basewarphq/bw/cmd/internal/tool"
 	"github.com/basewarphq/bw/cmd/internal/wscfg"
@@ -10,14 +11,17 @@ import (
 
 type InfraInspectCmd struct {
 	Deployment string   `arg:"" optional:"" help:"Deployment name (e.g., Stag, Prod). Defaults to claimed dev slot."`
-	Lens       []string `short:"l" help:"Run specific inspections (e.g. endpoints, logs, 1password-sync)."`
+	Lens       []string `short:"l" help:"Run specific inspections (e.g. endpoints, logs). Opt-in lenses (drift, health, dns, bootstrap) only run when selected."`
+	Strict     bool     `help:"Exit non-zero when a lens finds a problem (e.g. drift or a failing health check)."`
 }
 
 func (c *InfraInspectCmd) Run(cfg *wscfg.Config, reg *tool.Registry) error {
-	ctx := context.Background()
+	// Lenses read the same stacks; share one cache for the whole run.
+	ctx := cfnread.WithCache(context.Background())
 	if c.Deployment != "" {
 		ctx = tool.WithDeployment(ctx, c.Deployment)
 	}
+	ctx = tool.WithInspectOptions(ctx, tool.InspectOptions{Strict: c.Strict})
 	if len(c.Lens) > 0 {
 		ctx = tool.WithInspectSelection(ctx, c.Lens)
 	}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/basewarphq/bw/cmd/internal/cwlogs"
+	"github.com/basewarphq/bw/cmd/internal/tool/cdktool"
+	"github.com/basewarphq/bw/cmd/internal/wscfg"
+	"github.com/cockroachdb/errors"
+)
+
+type InfraLogsCmd struct {
+	Deployment string        `arg:"" optional:"" help:"Deployment name (e.g., Stag, Prod). Defaults to claimed dev slot."`
+	Function   string        `help:"Only tail log groups whose output key starts with this, any case (e.g., BackendCoreback)." short:"f"`
+	Since      time.Duration `help:"How far back to start." default:"10m"`
+	Follow     bool          `help:"Keep polling for new events." short:"F"`
+	Filter     string        `help:"CloudWatch Logs filter pattern."`
+	Trace      string        `help:"Only show events of this X-Ray trace id."`
+}
+
+func (c *InfraLogsCmd) Run(cfg *wscfg.Config) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	proj, err := cfg.FindProjectByTool("cdk")
+	if err != nil {
+		return err
+	}
+	groups, err := cdktool.LogGroups(ctx, cfg.ProjectToolConfig(proj.Name, "cdk"), cfg.ProjectDir(*proj), c.Deployment)
+	if err != nil {
+		return err
+	}
+	if c.Function != "" {
+		var matched []*cwlogs.Group
+		for _, g := range groups {
+			if strings.HasPrefix(strings.ToLower(g.Label), strings.ToLower(c.Function)) {
+				matched = append(matched, g)
+			}
+		}
+		groups = matched
+	}
+	if len(groups) == 0 {
+		return errors.New("no matching log groups found in the deployment's stack outputs")
+	}
+
+	pattern := c.Filter
+	if c.Trace != "" {
+		if pattern != "" {
+			return errors.New("--trace and --filter cannot be combined")
+		}
+		// The trace id appears verbatim in both access logs and function logs.
+		pattern = `"` + c.Trace + `"`
+	}
+
+	return cwlogs.Tail(ctx, os.Stdout, groups, cwlogs.Options{
+		Since:   c.Since,
+		Follow:  c.Follow,
+		Pattern: pattern,
+	})
+}
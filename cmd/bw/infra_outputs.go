@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/basewarphq/bw/cmd/internal/stackoutputs"
+	"github.com/basewarphq/bw/cmd/internal/tool/cdktool"
+	"github.com/basewarphq/bw/cmd/internal/wscfg"
+	"github.com/cockroachdb/errors"
+)
+
+type InfraOutputsCmd struct {
+	Deployment string `arg:"" optional:"" help:"Deployment name (e.g., Stag, Prod). Defaults to claimed dev slot."`
+	Format     string `help:"Output format." enum:"env,json,yaml" default:"env"`
+	Out        string `help:"Write to this file instead of stdout." type:"path" short:"o"`
+}
+
+func (c *InfraOutputsCmd) Run(cfg *wscfg.Config) error {
+	ctx := context.Background()
+
+	proj, err := cfg.FindProjectByTool("cdk")
+	if err != nil {
+		return err
+	}
+	outputs, err := cdktool.Outputs(ctx, cfg.ProjectToolConfig(proj.Name, "cdk"), cfg.ProjectDir(*proj), c.Deployment)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if c.Out != "" {
+		f, err := os.Create(c.Out)
+		if err != nil {
+			return errors.Wrap(err, "creating output file")
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := stackoutputs.Write(w, outputs, c.Format); err != nil {
+		return errors.Wrap(err, "writing outputs")
+	}
+	return nil
+}
@@ -1,24 +1,50 @@
 package main
 
 import (
+	"slices"
+	"strings"
+
+	"github.com/basewarphq/bw/cmd/internal/cdkctx"
+	"github.com/basewarphq/bw/cmd/internal/devslot"
 	"github.com/basewarphq/bw/cmd/internal/tool/cdktool"
 	"github.com/basewarphq/bw/cmd/internal/wscfg"
+	"github.com/cockroachdb/errors"
 )
 
 type InfraSlotsCmd struct {
-	Claim   InfraSlotClaimCmd   `cmd:"" help:"Claim a free dev deployment slot."`
-	Release InfraSlotReleaseCmd `cmd:"" help:"Release a claimed dev slot."`
-	Status  InfraSlotStatusCmd  `cmd:"" help:"Show status of all dev slots."`
+	Claim    InfraSlotClaimCmd    `cmd:"" help:"Claim a free deployment slot from a pool."`
+	Release  InfraSlotReleaseCmd  `cmd:"" help:"Release a claimed dev slot."`
+	Status   InfraSlotStatusCmd   `cmd:"" help:"Show status of all slots in a pool."`
+	Handover InfraSlotHandoverCmd `cmd:"" help:"Print a token that moves this checkout's claim to another machine."`
+	Adopt    InfraSlotAdoptCmd    `cmd:"" help:"Take over a claim using a token from 'bw infra slots handover'."`
+	History  InfraSlotHistoryCmd  `cmd:"" help:"Show the claim, release and deploy history of dev slots."`
 }
 
-func infraProjectDirAndProfile(cfg *wscfg.Config) (dir, profile string, err error) {
+// checkPoolSlot reports an error when slot is not one of the slots of the
+// selected pool.
+func checkPoolSlot(settings devslot.Settings, cctx *cdkctx.CDKContext, slot string) error {
+	slots, err := settings.PoolSlots(cctx)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(slots, slot) {
+		return errors.Newf("slot %q is not in pool %q, expected one of: %s",
+			slot, settings.PoolName(), strings.Join(slots, ", "))
+	}
+	return nil
+}
+
+func infraSlotSettings(cfg *wscfg.Config, pool string) (dir string, settings devslot.Settings, err error) {
 	proj, err := cfg.FindProjectByTool("cdk")
 	if err != nil {
-		return "", "", err
+		return "", devslot.Settings{}, err
 	}
 	dir = cfg.ProjectDir(*proj)
 	if tc := cfg.ProjectToolConfig(proj.Name, "cdk"); tc != nil {
-		profile = cdktool.ProfileFromConfig(tc)
+		settings = cdktool.SlotSettingsFromConfig(tc)
+	}
+	if pool != "" {
+		settings.Pool = pool
 	}
-	return dir, profile, nil
+	return dir, settings, nil
 }
@@ -9,17 +9,24 @@ import (
 	"github.com/basewarphq/bw/cmd/internal/wscfg"
 )
 
-type InfraSlotClaimCmd struct{}
+type InfraSlotClaimCmd struct {
+	Slot  string `help:"Claim this specific slot instead of the first free one." short:"s"`
+	Label string `help:"Label shown in slot status (default: git user@host). Relabels an existing claim." short:"l"`
+	Pool  string `help:"Slot pool to claim from (default: the configured default pool)."`
+}
 
 func (c *InfraSlotClaimCmd) Run(cfg *wscfg.Config) error {
 	ctx := context.Background()
 
-	dir, profile, err := infraProjectDirAndProfile(cfg)
+	dir, settings, err := infraSlotSettings(cfg, c.Pool)
 	if err != nil {
 		return err
 	}
 
-	claim, err := devslot.EnsureClaim(ctx, dir, profile)
+	claim, err := devslot.EnsureClaim(ctx, dir, settings, devslot.ClaimOptions{
+		Slot:  c.Slot,
+		Label: c.Label,
+	})
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/basewarphq/bw/cmd/internal/devslot"
+	"github.com/basewarphq/bw/cmd/internal/wscfg"
+)
+
+type InfraSlotHandoverCmd struct {
+	Slot string `arg:"" help:"Name of this checkout's claimed slot to hand over."`
+	Pool string `help:"Slot pool the slot belongs to (default: the configured default pool)."`
+}
+
+func (c *InfraSlotHandoverCmd) Run(cfg *wscfg.Config) error {
+	ctx := context.Background()
+
+	dir, settings, err := infraSlotSettings(cfg, c.Pool)
+	if err != nil {
+		return err
+	}
+
+	token, err := devslot.Handover(ctx, dir, settings, c.Slot)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Handed over %s; run 'bw infra slots adopt <token>' on the other machine.\n", c.Slot)
+	fmt.Fprintln(os.Stdout, token)
+	return nil
+}
+
+type InfraSlotAdoptCmd struct {
+	Token string `arg:"" help:"Token printed by 'bw infra slots handover'."`
+	Label string `help:"Label shown in slot status (default: git user@host)." short:"l"`
+	Pool  string `help:"Slot pool the handed over slot belongs to (default: the configured default pool)."`
+}
+
+func (c *InfraSlotAdoptCmd) Run(cfg *wscfg.Config) error {
+	ctx := context.Background()
+
+	dir, settings, err := infraSlotSettings(cfg, c.Pool)
+	if err != nil {
+		return err
+	}
+
+	claim, err := devslot.Adopt(ctx, dir, settings, c.Token, c.Label)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(os.Stdout, claim.Slot)
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/basewarphq/bw/cmd/internal/devslot"
+	"github.com/basewarphq/bw/cmd/internal/wscfg"
+	"github.com/cockroachdb/errors"
+)
+
+type InfraSlotHistoryCmd struct {
+	Slot  string `arg:"" optional:"" help:"Only show events for this slot."`
+	Limit int    `help:"Show at most this many of the most recent events (0 for all)." default:"50" short:"n"`
+	Pool  string `help:"Only show events for the slots of this pool."`
+}
+
+func (c *InfraSlotHistoryCmd) Run(cfg *wscfg.Config) error {
+	ctx := context.Background()
+
+	dir, settings, err := infraSlotSettings(cfg, c.Pool)
+	if err != nil {
+		return err
+	}
+
+	store, cctx, err := devslot.OpenStore(ctx, dir, settings, c.Slot)
+	if err != nil {
+		return err
+	}
+	if store.History == nil {
+		return errors.New("slot history is disabled (slots.history = \"none\")")
+	}
+	if c.Slot != "" && (!cctx.IsValidDeployment(c.Slot) || !settings.IsSlot(c.Slot)) {
+		return errors.Newf("%q is not a slot deployment", c.Slot)
+	}
+
+	var slots []string
+	switch {
+	case c.Slot != "":
+		slots = []string{c.Slot}
+	case c.Pool != "":
+		if slots, err = settings.PoolSlots(cctx); err != nil {
+			return err
+		}
+	}
+	events, err := store.History.List(ctx, slots)
+	if err != nil {
+		return err
+	}
+	if c.Limit > 0 && len(events) > c.Limit {
+		events = events[len(events)-c.Limit:]
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tSLOT\tACTION\tLABEL\tPREVIOUS LABEL\tACTOR")
+	for _, ev := range events {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			ev.Time, ev.Slot, ev.Action, ev.Label, ev.PreviousLabel, ev.Actor)
+	}
+	w.Flush()
+
+	return nil
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/basewarphq/bw/cmd/internal/cdkctx"
 	"github.com/basewarphq/bw/cmd/internal/devslot"
 	"github.com/basewarphq/bw/cmd/internal/wscfg"
 	"github.com/cockroachdb/errors"
@@ -14,31 +13,30 @@ import (
 type InfraSlotReleaseCmd struct {
 	Slot  string `help:"Name of the slot to release (default: this checkout's claimed slot)." short:"s"`
 	Force bool   `help:"Force-release the slot even if it belongs to someone else." short:"f"`
+	Pool  string `help:"Slot pool the slot belongs to (default: the configured default pool)."`
 }
 
 func (c *InfraSlotReleaseCmd) Run(cfg *wscfg.Config) error {
 	ctx := context.Background()
 
-	dir, profile, err := infraProjectDirAndProfile(cfg)
+	dir, settings, err := infraSlotSettings(cfg, c.Pool)
 	if err != nil {
 		return err
 	}
 
-	cctx, err := cdkctx.Load(dir)
+	slot, token, isLocalClaim, err := c.resolveSlot(dir)
 	if err != nil {
 		return err
 	}
 
-	accountID, err := devslot.AccountID(ctx, profile)
+	store, cctx, err := devslot.OpenStore(ctx, dir, settings, slot)
 	if err != nil {
 		return err
 	}
-
-	store := devslot.NewStore(cctx.BootstrapBucket(accountID), cctx.PrimaryRegion)
-
-	slot, token, isLocalClaim, err := c.resolveSlot(dir)
-	if err != nil {
-		return err
+	if c.Pool != "" {
+		if err := checkPoolSlot(settings, cctx, slot); err != nil {
+			return err
+		}
 	}
 
 	if c.Force {
@@ -2,69 +2,236 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/basewarphq/bw/cmd/internal/cdkctx"
+	"github.com/basewarphq/bw/cmd/internal/cfnread"
+	"github.com/basewarphq/bw/cmd/internal/cmdexec"
 	"github.com/basewarphq/bw/cmd/internal/devslot"
+	"github.com/basewarphq/bw/cmd/internal/parallel"
+	"github.com/basewarphq/bw/cmd/internal/tool/cdktool"
 	"github.com/basewarphq/bw/cmd/internal/wscfg"
-	"github.com/cockroachdb/errors"
 )
 
-type InfraSlotStatusCmd struct{}
+type InfraSlotStatusCmd struct {
+	Pool   string `help:"Slot pool to show (default: the configured default pool)."`
+	Stacks bool   `help:"Also look up the CloudFormation stacks of every slot in every region."`
+	JSON   bool   `help:"Print the status as JSON." name:"json"`
+}
+
+type slotStatus struct {
+	Slot     string            `json:"slot"`
+	Status   string            `json:"status"`
+	Mine     bool              `json:"mine"`
+	Label    string            `json:"label,omitempty"`
+	LastUsed string            `json:"last_used,omitempty"`
+	Stacks   []slotStackStatus `json:"stacks,omitempty"`
+	Notes    []string          `json:"notes,omitempty"`
+	Error    string            `json:"error,omitempty"`
+}
+
+type slotStackStatus struct {
+	Name        string `json:"name"`
+	Region      string `json:"region"`
+	Status      string `json:"status"`
+	LastUpdated string `json:"last_updated,omitempty"`
+	Commit      string `json:"commit,omitempty"`
+}
+
+const (
+	stackNotDeployed = "NOT_DEPLOYED"
+	stackLookupError = "LOOKUP_FAILED"
+
+	stackLookupConcurrency = 8
+)
 
 func (c *InfraSlotStatusCmd) Run(cfg *wscfg.Config) error {
 	ctx := context.Background()
 
-	dir, profile, err := infraProjectDirAndProfile(cfg)
+	dir, settings, err := infraSlotSettings(cfg, c.Pool)
 	if err != nil {
 		return err
 	}
 
-	cctx, err := cdkctx.Load(dir)
+	store, cctx, err := devslot.OpenStore(ctx, dir, settings, "")
 	if err != nil {
 		return err
 	}
 
-	slots := cctx.DevSlots()
-	if len(slots) == 0 {
-		return errors.New("no Dev* deployments defined in cdk.context.json")
-	}
-
-	accountID, err := devslot.AccountID(ctx, profile)
+	slots, err := settings.PoolSlots(cctx)
 	if err != nil {
 		return err
 	}
 
-	store := devslot.NewStore(cctx.BootstrapBucket(accountID), cctx.PrimaryRegion)
-
-	statuses, err := store.ListAll(ctx, slots)
+	locks, err := store.ListAll(ctx, slots)
 	if err != nil {
 		return err
 	}
 
 	claim, _ := devslot.ReadClaimFile(dir)
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
-	fmt.Fprintln(w, "SLOT\tSTATUS\tLABEL\tLAST USED")
-	for _, slot := range slots {
-		info := statuses[slot]
-		status := "free"
-		if info != nil {
-			status = "claimed"
-			if claim != nil && claim.Slot == slot {
-				status = "claimed (*)"
+	statuses := make([]slotStatus, len(locks))
+	for i, sl := range locks {
+		st := slotStatus{Slot: sl.Slot, Status: "free"}
+		switch {
+		case sl.Err != nil:
+			st.Status = "unknown"
+			st.Error = cmdexec.Summary(sl.Err)
+		case sl.Lock != nil:
+			st.Status = "claimed"
+			st.Mine = claim != nil && claim.Slot == sl.Slot
+			st.Label = sl.Lock.Label
+			st.LastUsed = sl.Lock.LastUsed
+		}
+		statuses[i] = st
+	}
+
+	if c.Stacks {
+		lookupSlotStacks(ctx, cctx, settings, statuses)
+	}
+
+	if c.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(statuses)
+	}
+
+	printSlotStatuses(statuses, c.Stacks)
+	return nil
+}
+
+func lookupSlotStacks(ctx context.Context, cctx *cdkctx.CDKContext, settings devslot.Settings, statuses []slotStatus) {
+	// listing is a region as seen through the profile of one account; slots
+	// mapped to different accounts are listed separately.
+	type listing struct{ region, profile string }
+	type lookup struct {
+		slot    int
+		ref     cdkctx.StackRef
+		listing listing
+	}
+	var lookups []lookup
+	var listings []listing
+	for i := range statuses {
+		profile := settings.AccountProfile(cctx.DeploymentAccount(statuses[i].Slot))
+		for _, ref := range cctx.DeploymentStacks(statuses[i].Slot) {
+			l := listing{region: ref.Region, profile: profile}
+			if !slices.Contains(listings, l) {
+				listings = append(listings, l)
 			}
+			lookups = append(lookups, lookup{slot: i, ref: ref, listing: l})
+		}
+	}
+
+	// One paginated listing per region and account answers every slot's
+	// stacks.
+	listed := make([]map[string]*cfnread.Stack, len(listings))
+	listErrs := make([]error, len(listings))
+	parallel.ForEach(len(listings), stackLookupConcurrency, func(i int) {
+		stacks, err := cfnread.DescribeStacks(ctx, listings[i].region, listings[i].profile, cctx.Qualifier)
+		listed[i], listErrs[i] = make(map[string]*cfnread.Stack, len(stacks)), err
+		for _, stack := range stacks {
+			listed[i][stack.Name] = stack
+		}
+	})
+
+	results := make([]slotStackStatus, len(lookups))
+	errs := make([]error, len(lookups))
+	for i, lk := range lookups {
+		ref := lk.ref
+		results[i] = slotStackStatus{Name: ref.Name, Region: ref.Region, Status: stackNotDeployed}
+		r := slices.Index(listings, lk.listing)
+		if listErrs[r] != nil {
+			results[i].Status = stackLookupError
+			errs[i] = listErrs[r]
+			continue
 		}
-		if info == nil {
-			fmt.Fprintf(w, "%s\t%s\t\t\n", slot, status)
-		} else {
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
-				slot, status, info.Label, info.LastUsed)
+		if stack, ok := listed[r][ref.Name]; ok {
+			results[i].Status = stack.Status
+			results[i].LastUpdated = stack.LastUpdatedTime
+			results[i].Commit = cdktool.DeployedCommit(stack)
 		}
 	}
-	w.Flush()
 
-	return nil
+	deployed := make([]int, len(statuses))
+	for i, lk := range lookups {
+		st := &statuses[lk.slot]
+		st.Stacks = append(st.Stacks, results[i])
+		switch {
+		case errs[i] != nil:
+			st.Error = joinErrors(st.Error, cmdexec.Summary(errs[i]))
+		case results[i].Status != stackNotDeployed:
+			deployed[lk.slot]++
+		}
+	}
+
+	for i := range statuses {
+		st := &statuses[i]
+		switch {
+		case st.Error != "":
+		case st.Status == "free" && deployed[i] > 0:
+			st.Notes = append(st.Notes, "free but stacks are still deployed")
+		case st.Status == "claimed" && deployed[i] == 0:
+			st.Notes = append(st.Notes, "claimed but never deployed")
+		}
+	}
+}
+
+func joinErrors(existing, msg string) string {
+	if existing == "" {
+		return msg
+	}
+	return existing + "; " + msg
+}
+
+func printSlotStatuses(statuses []slotStatus, withStacks bool) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	defer w.Flush()
+
+	if !withStacks {
+		fmt.Fprintln(w, "SLOT\tSTATUS\tLABEL\tLAST USED\tERROR")
+		for _, st := range statuses {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", st.Slot, displayStatus(st), st.Label, st.LastUsed, st.Error)
+		}
+		return
+	}
+
+	fmt.Fprintln(w, "SLOT\tSTATUS\tLABEL\tLAST USED\tREGION\tSTACK STATUS\tUPDATED\tCOMMIT\tNOTES")
+	for _, st := range statuses {
+		notes := strings.Join(st.Notes, "; ")
+		if st.Error != "" {
+			notes = "error: " + st.Error
+		}
+		for i, ss := range st.Stacks {
+			if i == 0 {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t", st.Slot, displayStatus(st), st.Label, st.LastUsed)
+			} else {
+				fmt.Fprint(w, "\t\t\t\t")
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", ss.Region, ss.Status, ss.LastUpdated, shortCommit(ss.Commit), notes)
+			notes = ""
+		}
+	}
+}
+
+func displayStatus(st slotStatus) string {
+	if st.Mine {
+		return st.Status + " (*)"
+	}
+	return st.Status
+}
+
+func shortCommit(commit string) string {
+	sha, dirty := strings.CutSuffix(commit, "-dirty")
+	if len(sha) > 12 {
+		sha = sha[:12]
+	}
+	if dirty {
+		return sha + "-dirty"
+	}
+	return sha
 }
@@ -43,7 +43,12 @@ type App struct {
 		Diff      InfraDiffCmd      `cmd:"" help:"Show infrastructure diff for a deployment."`
 		Deploy    InfraDeployCmd    `cmd:"" help:"Deploy infrastructure stacks for a deployment."`
 		Inspect   InfraInspectCmd   `cmd:"" help:"Inspect deployment. Use -l to select lenses."`
+		Destroy   InfraDestroyCmd   `cmd:"" help:"Destroy the stacks of a deployment."`
+		Outputs   InfraOutputsCmd   `cmd:"" help:"Export the stack outputs of a deployment as env, JSON or YAML."`
+		Logs      InfraLogsCmd      `cmd:"" help:"Tail the CloudWatch logs of a deployment across regions."`
+		DNS       InfraDNSCmd       `cmd:"" name:"dns" help:"Check DNS delegation of the base domain."`
 		Slots     InfraSlotsCmd     `cmd:"" help:"Manage dev deployment slots."`
+		Context   InfraContextCmd   `cmd:"" help:"Read and edit cdk.context.json."`
 	} `cmd:"" help:"Infrastructure commands."`
 }
 
@@ -2,10 +2,11 @@ package cdkctx
 
 import (
 	"encoding/json"
+	"maps"
 	"os"
+	"path"
 	"path/filepath"
 	"slices"
-	"sort"
 	"strings"
 
 	"github.com/basewarphq/bw/bwcdk/bwcdkutil"
@@ -13,22 +14,40 @@ import (
 )
 
 type CDKContext struct {
-	Qualifier     string
-	Prefix        string
-	PrimaryRegion string
-	Deployments   []string
-	RegionIdents  map[string]string
-	ContextValues map[string]string
+	Qualifier        string
+	Prefix           string
+	PrimaryRegion    string
+	SecondaryRegions []string
+	Deployments      []string
+	ContextValues    map[string]string
+	// DNSDelegated mirrors the {prefix}dns-delegated flag that gates
+	// certificate creation until the parent zone delegates to ours.
+	DNSDelegated bool
+	// DeploymentAccounts mirrors {prefix}deployment-accounts, which maps
+	// deployment names or patterns to AWS account IDs. Nil when the app
+	// deploys everything to a single account.
+	DeploymentAccounts map[string]string
+
+	legacyRegionIdents map[string]string
+	// values holds every context value, for validation by bwcdkutil.
+	values map[string]any
+}
+
+type StackRef struct {
+	Name   string
+	Region string
 }
 
+// Load reads the context of the CDK app in cdkDir. Like the cdk CLI, it
+// merges cdk.context.json with the context in cdk.json, which takes
+// precedence. Only the keys the CLI needs are checked; Validate applies the
+// full rules of the app.
 func Load(cdkDir string) (*CDKContext, error) {
-	qualifier, err := readQualifier(cdkDir)
+	qualifier, projectCtx, err := readProjectContext(cdkDir)
 	if err != nil {
 		return nil, err
 	}
 
-	prefix := qualifier + "-"
-
 	ctxFile := filepath.Join(cdkDir, "cdk.context.json")
 	ctxData, err := os.ReadFile(ctxFile)
 	if err != nil {
@@ -39,6 +58,19 @@ func Load(cdkDir string) (*CDKContext, error) {
 	if err := json.Unmarshal(ctxData, &ctxMap); err != nil {
 		return nil, errors.Wrapf(err, "parsing %s", ctxFile)
 	}
+	return parse(qualifier, ctxFile, mergeContext(ctxMap, projectCtx))
+}
+
+// mergeContext overlays the cdk.json context on the cdk.context.json values.
+func mergeContext(ctxMap, projectCtx map[string]json.RawMessage) map[string]json.RawMessage {
+	merged := make(map[string]json.RawMessage, len(ctxMap)+len(projectCtx))
+	maps.Copy(merged, ctxMap)
+	maps.Copy(merged, projectCtx)
+	return merged
+}
+
+func parse(qualifier, ctxFile string, ctxMap map[string]json.RawMessage) (*CDKContext, error) {
+	prefix := qualifier + "-"
 
 	primaryRegion, err := getString(ctxMap, prefix+"primary-region")
 	if err != nil {
@@ -50,23 +82,42 @@ func Load(cdkDir string) (*CDKContext, error) {
 		return nil, errors.Wrapf(err, "in %s", ctxFile)
 	}
 
-	regionIdents := make(map[string]string)
-	regionIdentPrefix := prefix + "region-ident-"
-	for key := range ctxMap {
-		if !strings.HasPrefix(key, regionIdentPrefix) {
+	var secondaryRegions []string
+	if _, ok := ctxMap[prefix+"secondary-regions"]; ok {
+		secondaryRegions, err = getStringSlice(ctxMap, prefix+"secondary-regions")
+		if err != nil {
+			return nil, errors.Wrapf(err, "in %s", ctxFile)
+		}
+	}
+
+	// Like bwcdkutil in legacy mode, only the configured regions have idents.
+	legacyRegionIdents := make(map[string]string)
+	for _, region := range append([]string{primaryRegion}, secondaryRegions...) {
+		key := prefix + "region-ident-" + region
+		if _, ok := ctxMap[key]; !ok {
 			continue
 		}
-		region := strings.TrimPrefix(key, regionIdentPrefix)
 		ident, err := getString(ctxMap, key)
 		if err != nil {
 			return nil, errors.Wrapf(err, "in %s", ctxFile)
 		}
-		regionIdents[ident] = region
+		legacyRegionIdents[region] = ident
 	}
-	for region, ident := range bwcdkutil.RegionIdents {
-		if _, ok := regionIdents[ident]; !ok {
-			regionIdents[ident] = region
+
+	var deploymentAccounts map[string]string
+	if raw, ok := ctxMap[prefix+"deployment-accounts"]; ok {
+		if err := json.Unmarshal(raw, &deploymentAccounts); err != nil {
+			return nil, errors.Wrapf(err, "context key %q in %s", prefix+"deployment-accounts", ctxFile)
+		}
+	}
+
+	values := make(map[string]any, len(ctxMap))
+	for key, raw := range ctxMap {
+		var v any
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, errors.Wrapf(err, "context key %q in %s", key, ctxFile)
 		}
+		values[key] = v
 	}
 
 	contextValues := make(map[string]string)
@@ -86,80 +137,117 @@ func Load(cdkDir string) (*CDKContext, error) {
 		}
 	}
 
+	// Like bwcdkutil, anything but a JSON true leaves the flag off.
+	var dnsDelegated bool
+	_ = json.Unmarshal(ctxMap[prefix+"dns-delegated"], &dnsDelegated)
+
 	return &CDKContext{
-		Qualifier:     qualifier,
-		Prefix:        prefix,
-		PrimaryRegion: primaryRegion,
-		Deployments:   deployments,
-		RegionIdents:  regionIdents,
-		ContextValues: contextValues,
+		Qualifier:          qualifier,
+		Prefix:             prefix,
+		PrimaryRegion:      primaryRegion,
+		SecondaryRegions:   secondaryRegions,
+		Deployments:        deployments,
+		ContextValues:      contextValues,
+		DNSDelegated:       dnsDelegated,
+		DeploymentAccounts: deploymentAccounts,
+		legacyRegionIdents: legacyRegionIdents,
+		values:             values,
 	}, nil
 }
 
-func (c *CDKContext) DevSlots() []string {
-	var slots []string
+// DeploymentsMatching returns the deployments whose name matches the
+// path.Match glob pattern, in cdk.context.json order.
+func (c *CDKContext) DeploymentsMatching(pattern string) []string {
+	var matched []string
 	for _, d := range c.Deployments {
-		if strings.HasPrefix(d, "Dev") {
-			slots = append(slots, d)
+		if ok, _ := path.Match(pattern, d); ok {
+			matched = append(matched, d)
 		}
 	}
-	return slots
+	return matched
 }
 
-func (c *CDKContext) BootstrapBucket(accountID string) string {
-	return "cdk-" + c.Qualifier + "-assets-" + accountID + "-" + c.PrimaryRegion
+// DeploymentAccount returns the AWS account of a deployment, or "" when the
+// app does not map deployments to accounts.
+func (c *CDKContext) DeploymentAccount(deployment string) string {
+	return bwcdkutil.DeploymentAccount(c.DeploymentAccounts, deployment)
 }
 
-func (c *CDKContext) IsValidDeployment(name string) bool {
-	return slices.Contains(c.Deployments, name)
+func (c *CDKContext) AllRegions() []string {
+	return append([]string{c.PrimaryRegion}, c.SecondaryRegions...)
 }
 
-func (c *CDKContext) ResolveStackRegion(stackName string) (string, bool) {
-	rest := strings.TrimPrefix(stackName, c.Qualifier)
-	if rest == stackName {
-		return "", false
+func (c *CDKContext) RegionIdent(region string) string {
+	if ident, ok := c.legacyRegionIdents[region]; ok {
+		return ident
 	}
+	return bwcdkutil.RegionIdents[region]
+}
 
-	idents := make([]string, 0, len(c.RegionIdents))
-	for ident := range c.RegionIdents {
-		idents = append(idents, ident)
+// ParseStackName takes apart a stack name of this app, taking legacy region
+// idents into account.
+func (c *CDKContext) ParseStackName(name string) (bwcdkutil.StackName, bool) {
+	return bwcdkutil.ParseLegacyStackName(c.Qualifier, name, c.legacyRegionIdents)
+}
+
+func (c *CDKContext) DeploymentStacks(deployment string) []StackRef {
+	regions := c.AllRegions()
+	stacks := make([]StackRef, 0, len(regions))
+	for _, region := range regions {
+		stacks = append(stacks, StackRef{
+			Name:   bwcdkutil.DeploymentStackName(c.Qualifier, c.RegionIdent(region), deployment),
+			Region: region,
+		})
 	}
-	sort.Slice(idents, func(i, j int) bool {
-		return len(idents[i]) > len(idents[j])
-	})
+	return stacks
+}
 
-	for _, ident := range idents {
-		if strings.HasPrefix(rest, ident) {
-			return c.RegionIdents[ident], true
-		}
+func (c *CDKContext) SharedStacks() []StackRef {
+	regions := c.AllRegions()
+	stacks := make([]StackRef, 0, len(regions))
+	for _, region := range regions {
+		stacks = append(stacks, StackRef{
+			Name:   bwcdkutil.SharedStackName(c.Qualifier, c.RegionIdent(region)),
+			Region: region,
+		})
 	}
-	return "", false
+	return stacks
+}
+
+func (c *CDKContext) BootstrapBucket(accountID string) string {
+	return "cdk-" + c.Qualifier + "-assets-" + accountID + "-" + c.PrimaryRegion
+}
+
+func (c *CDKContext) IsValidDeployment(name string) bool {
+	return slices.Contains(c.Deployments, name)
 }
 
-func readQualifier(cdkDir string) (string, error) {
+// readProjectContext reads the bootstrap qualifier and the context from
+// cdk.json.
+func readProjectContext(cdkDir string) (string, map[string]json.RawMessage, error) {
 	cdkJSON := filepath.Join(cdkDir, "cdk.json")
 	data, err := os.ReadFile(cdkJSON)
 	if err != nil {
-		return "", errors.Wrapf(err, "reading %s", cdkJSON)
+		return "", nil, errors.Wrapf(err, "reading %s", cdkJSON)
 	}
 
 	var cfg struct {
 		Context map[string]json.RawMessage `json:"context"`
 	}
 	if err := json.Unmarshal(data, &cfg); err != nil {
-		return "", errors.Wrapf(err, "parsing %s", cdkJSON)
+		return "", nil, errors.Wrapf(err, "parsing %s", cdkJSON)
 	}
 
 	raw, ok := cfg.Context["@aws-cdk/core:bootstrapQualifier"]
 	if !ok {
-		return "", errors.Newf("missing @aws-cdk/core:bootstrapQualifier in %s", cdkJSON)
+		return "", nil, errors.Newf("missing @aws-cdk/core:bootstrapQualifier in %s", cdkJSON)
 	}
 
 	var qualifier string
 	if err := json.Unmarshal(raw, &qualifier); err != nil {
-		return "", errors.Newf("@aws-cdk/core:bootstrapQualifier must be a string in %s", cdkJSON)
+		return "", nil, errors.Newf("@aws-cdk/core:bootstrapQualifier must be a string in %s", cdkJSON)
 	}
-	return qualifier, nil
+	return qualifier, cfg.Context, nil
 }
 
 func getString(m map[string]json.RawMessage, key string) (string, error) {
@@ -0,0 +1,310 @@
+package cdkctx_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/basewarphq/bw/cmd/internal/cdkctx"
+	"github.com/basewarphq/bw/cmd/internal/testutil"
+)
+
+const cdkJSON = `{"context": {"@aws-cdk/core:bootstrapQualifier": "bwapp"}}`
+
+func TestDeploymentStacks(t *testing.T) {
+	t.Parallel()
+	dir := testutil.Setup(t, map[string]string{
+		"cdk.json": cdkJSON,
+		"cdk.context.json": `{
+			"bwapp-primary-region": "eu-central-1",
+			"bwapp-secondary-regions": ["eu-west-1"],
+			"bwapp-deployments": ["Prod", "Dev01"]
+		}`,
+	})
+
+	cctx, err := cdkctx.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := cctx.DeploymentStacks("Dev01")
+	want := []cdkctx.StackRef{
+		{Name: "bwappEuc1Dev01", Region: "eu-central-1"},
+		{Name: "bwappEuw1Dev01", Region: "eu-west-1"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("stack %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestDeploymentStacks_LegacyRegionIdent(t *testing.T) {
+	t.Parallel()
+	dir := testutil.Setup(t, map[string]string{
+		"cdk.json": cdkJSON,
+		"cdk.context.json": `{
+			"bwapp-primary-region": "eu-central-1",
+			"bwapp-region-ident-eu-central-1": "De",
+			"bwapp-deployments": ["Prod"]
+		}`,
+	})
+
+	cctx, err := cdkctx.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := cctx.DeploymentStacks("Prod")
+	if len(got) != 1 || got[0].Name != "bwappDeProd" {
+		t.Errorf("got %+v, want single stack bwappDeProd", got)
+	}
+
+	sn, ok := cctx.ParseStackName("bwappDeProd")
+	if !ok || sn.Region != "eu-central-1" || sn.Deployment != "Prod" {
+		t.Errorf("ParseStackName: got %+v, %v", sn, ok)
+	}
+}
+
+func TestDeploymentsMatching(t *testing.T) {
+	t.Parallel()
+	dir := testutil.Setup(t, map[string]string{
+		"cdk.json": cdkJSON,
+		"cdk.context.json": `{
+			"bwapp-primary-region": "eu-central-1",
+			"bwapp-deployments": ["Prod", "Dev01", "Pr01", "Dev02", "Pr02"]
+		}`,
+	})
+
+	cctx, err := cdkctx.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := cctx.DeploymentsMatching("Dev*")
+	if len(got) != 2 || got[0] != "Dev01" || got[1] != "Dev02" {
+		t.Errorf("got %v, want [Dev01 Dev02]", got)
+	}
+	if got := cctx.DeploymentsMatching("Stag*"); len(got) != 0 {
+		t.Errorf("got %v, want none", got)
+	}
+}
+
+func TestDeploymentAccount(t *testing.T) {
+	t.Parallel()
+	dir := testutil.Setup(t, map[string]string{
+		"cdk.json": cdkJSON,
+		"cdk.context.json": `{
+			"bwapp-primary-region": "eu-central-1",
+			"bwapp-deployments": ["Prod", "Dev01"],
+			"bwapp-deployment-accounts": {"Prod": "111111111111", "Dev*": "222222222222"}
+		}`,
+	})
+
+	cctx, err := cdkctx.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for deployment, want := range map[string]string{
+		"Prod":  "111111111111",
+		"Dev01": "222222222222",
+		"Stag":  "",
+	} {
+		if got := cctx.DeploymentAccount(deployment); got != want {
+			t.Errorf("DeploymentAccount(%q) = %q, want %q", deployment, got, want)
+		}
+	}
+}
+
+func TestSetValue(t *testing.T) {
+	t.Parallel()
+	dir := testutil.Setup(t, map[string]string{
+		"cdk.json": cdkJSON,
+		"cdk.context.json": `{
+  "bwapp-primary-region": "eu-central-1",
+  "bwapp-deployments": ["Prod", "Dev01"],
+  "bwapp-dns-delegated": false
+}`,
+	})
+
+	if err := cdkctx.SetValue(dir, "bwapp-dns-delegated", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := cdkctx.SetValue(dir, "bwapp-base-domain-name", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "cdk.context.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "bwapp-primary-region": "eu-central-1",
+  "bwapp-deployments": [
+    "Prod",
+    "Dev01"
+  ],
+  "bwapp-dns-delegated": true,
+  "bwapp-base-domain-name": "example.com"
+}
+`
+	if string(data) != want {
+		t.Errorf("got\n%s\nwant\n%s", data, want)
+	}
+
+	cctx, err := cdkctx.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cctx.DNSDelegated {
+		t.Error("expected DNSDelegated after setting it")
+	}
+}
+
+const validContext = `{
+  "bwapp-qualifier": "bwapp",
+  "bwapp-primary-region": "eu-central-1",
+  "bwapp-secondary-regions": [],
+  "bwapp-deployments": ["Prod", "Dev01"],
+  "bwapp-base-domain-name": "example.com"
+}`
+
+func TestEdit(t *testing.T) {
+	t.Parallel()
+	dir := testutil.Setup(t, map[string]string{
+		"cdk.json":         cdkJSON,
+		"cdk.context.json": validContext,
+	})
+
+	if err := cdkctx.AddDeployment(dir, "Stag"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cdkctx.AddRegion(dir, "eu-west-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cdkctx.RemoveDeployment(dir, "Dev01"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cdkctx.Set(dir, "base-domain-name", "example.org"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "cdk.context.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "bwapp-qualifier": "bwapp",
+  "bwapp-primary-region": "eu-central-1",
+  "bwapp-secondary-regions": [
+    "eu-west-1"
+  ],
+  "bwapp-deployments": [
+    "Prod",
+    "Stag"
+  ],
+  "bwapp-base-domain-name": "example.org"
+}
+`
+	if string(data) != want {
+		t.Errorf("got\n%s\nwant\n%s", data, want)
+	}
+
+	got, err := cdkctx.Get(dir, "bwapp-deployments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "[\n    \"Prod\",\n    \"Stag\"\n  ]" {
+		t.Errorf("Get: got %s", got)
+	}
+}
+
+func TestEdit_Refused(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		edit    func(dir string) error
+		wantErr string
+	}{
+		{"duplicate deployment", func(dir string) error { return cdkctx.AddDeployment(dir, "Dev01") }, "already exists"},
+		{"invalid deployment name", func(dir string) error { return cdkctx.AddDeployment(dir, "dev-3") }, "upper-case"},
+		{"remove prod", func(dir string) error { return cdkctx.RemoveDeployment(dir, "Prod") }, `named "Prod"`},
+		{"unknown deployment", func(dir string) error { return cdkctx.RemoveDeployment(dir, "Dev09") }, "unknown"},
+		{"unknown region", func(dir string) error { return cdkctx.AddRegion(dir, "mars-east-1") }, "unknown secondary"},
+		{"primary region again", func(dir string) error { return cdkctx.AddRegion(dir, "eu-central-1") }, "already"},
+		{"bad domain", func(dir string) error { return cdkctx.Set(dir, "base-domain-name", "not a domain") }, "valid domain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			dir := testutil.Setup(t, map[string]string{
+				"cdk.json":         cdkJSON,
+				"cdk.context.json": validContext,
+			})
+
+			err := tt.edit(dir)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+			}
+			data, err := os.ReadFile(filepath.Join(dir, "cdk.context.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != validContext {
+				t.Errorf("file changed after a refused edit:\n%s", data)
+			}
+		})
+	}
+}
+
+func TestValidate_Qualifier(t *testing.T) {
+	t.Parallel()
+	dir := testutil.Setup(t, map[string]string{
+		"cdk.json": `{"context": {"@aws-cdk/core:bootstrapQualifier": "waytoolongqualifier"}}`,
+		"cdk.context.json": `{
+			"waytoolongqualifier-qualifier": "waytoolongqualifier",
+			"waytoolongqualifier-primary-region": "eu-central-1",
+			"waytoolongqualifier-secondary-regions": [],
+			"waytoolongqualifier-deployments": ["Prod"],
+			"waytoolongqualifier-base-domain-name": "example.com"
+		}`,
+	})
+
+	cctx, err := cdkctx.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cctx.Validate(); err == nil || !strings.Contains(err.Error(), "maximum length of 10") {
+		t.Errorf("got %v, want a qualifier length error", err)
+	}
+}
+
+func TestValidate_ProjectContext(t *testing.T) {
+	t.Parallel()
+	dir := testutil.Setup(t, map[string]string{
+		"cdk.json": `{"context": {
+			"@aws-cdk/core:bootstrapQualifier": "bwapp",
+			"bwapp-qualifier": "other",
+			"bwapp-deployments": ["Prod", "Stag"]
+		}}`,
+		"cdk.context.json": validContext,
+	})
+
+	cctx, err := cdkctx.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// cdk.json takes precedence over cdk.context.json, like in the cdk CLI.
+	if len(cctx.Deployments) != 2 || cctx.Deployments[1] != "Stag" {
+		t.Errorf("got deployments %v, want [Prod Stag]", cctx.Deployments)
+	}
+	if err := cctx.Validate(); err == nil || !strings.Contains(err.Error(), "bootstrap qualifier") {
+		t.Errorf("got %v, want a qualifier mismatch error", err)
+	}
+}
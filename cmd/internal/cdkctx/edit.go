@@ -0,0 +1,233 @@
+package cdkctx
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+// SetValue sets a key in cdk.context.json. Other keys keep their order and
+// values so the file diffs cleanly; a new key is appended.
+func SetValue(cdkDir, key string, value any) error {
+	f, err := readContextFile(cdkDir)
+	if err != nil {
+		return err
+	}
+	if err := f.set(key, value); err != nil {
+		return err
+	}
+	return f.write()
+}
+
+// Get returns the raw JSON value of a context key as the cdk CLI sees it.
+// Keys without the qualifier prefix get it added, so "deployments" reads
+// {prefix}deployments.
+func Get(cdkDir, key string) (json.RawMessage, error) {
+	qualifier, projectCtx, err := readProjectContext(cdkDir)
+	if err != nil {
+		return nil, err
+	}
+	f, err := readContextFile(cdkDir)
+	if err != nil {
+		return nil, err
+	}
+	key = prefixedKey(qualifier+"-", key)
+	value, ok := mergeContext(f.values, projectCtx)[key]
+	if !ok {
+		return nil, errors.Newf("context key %q is not set", key)
+	}
+	return value, nil
+}
+
+// Set sets a context key like SetValue, but only writes the file when the
+// result still passes Validate. The key is prefixed like in Get.
+func Set(cdkDir, key string, value any) error {
+	return edit(cdkDir, func(c *CDKContext, f *contextFile) error {
+		return f.set(prefixedKey(c.Prefix, key), value)
+	})
+}
+
+// AddDeployment appends a deployment to {prefix}deployments.
+func AddDeployment(cdkDir, name string) error {
+	return edit(cdkDir, func(c *CDKContext, f *contextFile) error {
+		if c.IsValidDeployment(name) {
+			return errors.Newf("deployment %q already exists", name)
+		}
+		if !isDeploymentName(name) {
+			return errors.Newf("deployment %q must start with an upper-case letter and contain only letters and digits",
+				name)
+		}
+		return f.set(c.Prefix+"deployments", append(c.Deployments, name))
+	})
+}
+
+// RemoveDeployment removes a deployment from {prefix}deployments. It does not
+// check for deployed stacks; callers that can reach AWS do that first.
+func RemoveDeployment(cdkDir, name string) error {
+	return edit(cdkDir, func(c *CDKContext, f *contextFile) error {
+		if !c.IsValidDeployment(name) {
+			return errors.Newf("unknown deployment %q", name)
+		}
+		return f.set(c.Prefix+"deployments", slices.DeleteFunc(c.Deployments, func(d string) bool {
+			return d == name
+		}))
+	})
+}
+
+// AddRegion appends a region to {prefix}secondary-regions.
+func AddRegion(cdkDir, region string) error {
+	return edit(cdkDir, func(c *CDKContext, f *contextFile) error {
+		if slices.Contains(c.AllRegions(), region) {
+			return errors.Newf("region %q is already configured", region)
+		}
+		return f.set(c.Prefix+"secondary-regions", append(slices.Clone(c.SecondaryRegions), region))
+	})
+}
+
+// edit applies fn to cdk.context.json and writes the result back if it
+// passes Validate. The file is left untouched when fn or validation fails.
+func edit(cdkDir string, fn func(c *CDKContext, f *contextFile) error) error {
+	qualifier, projectCtx, err := readProjectContext(cdkDir)
+	if err != nil {
+		return err
+	}
+	f, err := readContextFile(cdkDir)
+	if err != nil {
+		return err
+	}
+	current, err := parse(qualifier, f.path, mergeContext(f.values, projectCtx))
+	if err != nil {
+		return err
+	}
+	if err := fn(current, f); err != nil {
+		return err
+	}
+
+	updated, err := parse(qualifier, f.path, mergeContext(f.values, projectCtx))
+	if err != nil {
+		return err
+	}
+	if err := updated.Validate(); err != nil {
+		return errors.Wrapf(err, "not writing %s", f.path)
+	}
+	return f.write()
+}
+
+// isDeploymentName reports whether name fits into stack names like
+// bwappEuc1Dev01, which CloudFormation limits to letters, digits and hyphens.
+func isDeploymentName(name string) bool {
+	for i, r := range name {
+		switch {
+		case r >= 'A' && r <= 'Z':
+		case i > 0 && (r >= 'a' && r <= 'z' || r >= '0' && r <= '9'):
+		default:
+			return false
+		}
+	}
+	return name != ""
+}
+
+func prefixedKey(prefix, key string) string {
+	if strings.HasPrefix(key, prefix) {
+		return key
+	}
+	return prefix + key
+}
+
+// contextFile is cdk.context.json with its key order preserved.
+type contextFile struct {
+	path   string
+	keys   []string
+	values map[string]json.RawMessage
+}
+
+func readContextFile(cdkDir string) (*contextFile, error) {
+	ctxFile := filepath.Join(cdkDir, "cdk.context.json")
+	data, err := os.ReadFile(ctxFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %s", ctxFile)
+	}
+
+	keys, values, err := decodeOrdered(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing %s", ctxFile)
+	}
+	return &contextFile{path: ctxFile, keys: keys, values: values}, nil
+}
+
+func (f *contextFile) set(key string, value any) error {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return errors.Wrapf(err, "encoding %s", key)
+	}
+	if _, ok := f.values[key]; !ok {
+		f.keys = append(f.keys, key)
+	}
+	f.values[key] = encoded
+	return nil
+}
+
+func (f *contextFile) write() error {
+	var buf bytes.Buffer
+	buf.WriteString("{\n")
+	for i, k := range f.keys {
+		name, _ := json.Marshal(k)
+		var val bytes.Buffer
+		if err := json.Indent(&val, f.values[k], "  ", "  "); err != nil {
+			return errors.Wrapf(err, "formatting %s", k)
+		}
+		buf.WriteString("  ")
+		buf.Write(name)
+		buf.WriteString(": ")
+		buf.Write(val.Bytes())
+		if i < len(f.keys)-1 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('\n')
+	}
+	buf.WriteString("}\n")
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return errors.Wrapf(err, "reading %s", f.path)
+	}
+	if err := os.WriteFile(f.path, buf.Bytes(), info.Mode().Perm()); err != nil {
+		return errors.Wrapf(err, "writing %s", f.path)
+	}
+	return nil
+}
+
+func decodeOrdered(data []byte) ([]string, map[string]json.RawMessage, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, nil, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, nil, errors.New("expected a JSON object")
+	}
+
+	var keys []string
+	values := map[string]json.RawMessage{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		key, _ := tok.(string)
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, nil, err
+		}
+		if _, dup := values[key]; !dup {
+			keys = append(keys, key)
+		}
+		values[key] = raw
+	}
+	return keys, values, nil
+}
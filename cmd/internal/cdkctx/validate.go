@@ -0,0 +1,33 @@
+package cdkctx
+
+import (
+	"github.com/basewarphq/bw/bwcdk/bwcdkutil"
+	"github.com/cockroachdb/errors"
+)
+
+// AppConfig returns the bwcdkutil settings the CDK app is assumed to use:
+// the qualifier as key prefix, and legacy region idents when the context
+// defines any.
+func (c *CDKContext) AppConfig() bwcdkutil.AppConfig {
+	return bwcdkutil.AppConfig{
+		Prefix:            c.Prefix,
+		LegacyRegionIdent: len(c.legacyRegionIdents) > 0,
+	}
+}
+
+// Validate checks the context with bwcdkutil.LoadConfig, the rules
+// SetupApp applies at synth time, so a context that would make `cdk synth`
+// panic is reported before it is written or deployed. It also checks that
+// {prefix}qualifier matches the bootstrap qualifier in cdk.json, which
+// names the stacks.
+func (c *CDKContext) Validate() error {
+	cfg, err := bwcdkutil.LoadConfig(c.values, c.AppConfig())
+	if err != nil {
+		return err
+	}
+	if cfg.Qualifier != c.Qualifier {
+		return errors.Newf("context key %q is %q, but the bootstrap qualifier in cdk.json is %q",
+			c.Prefix+"qualifier", cfg.Qualifier, c.Qualifier)
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+// Package cdkdiff summarizes the text output of `cdk diff --no-color`.
+package cdkdiff
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+type Change string
+
+const (
+	ChangeAdd        Change = "add"
+	ChangeModify     Change = "modify"
+	ChangeReplace    Change = "replace"
+	ChangeMayReplace Change = "may-replace"
+	ChangeRemove     Change = "remove"
+	// ChangeOrphan is a removal of a resource whose deletion policy retains
+	// it, so its data survives.
+	ChangeOrphan Change = "orphan"
+)
+
+type ResourceChange struct {
+	Change    Change `json:"change"`
+	Type      string `json:"type"`
+	Path      string `json:"path,omitempty"`
+	LogicalID string `json:"logical_id"`
+	Stateful  bool   `json:"stateful"`
+}
+
+// Risky reports whether the change can lose data: a replacement or removal of
+// a stateful resource.
+func (c ResourceChange) Risky() bool {
+	if !c.Stateful {
+		return false
+	}
+	switch c.Change {
+	case ChangeReplace, ChangeMayReplace, ChangeRemove:
+		return true
+	default:
+		return false
+	}
+}
+
+type IAMChange struct {
+	Change Change `json:"change"`
+	Detail string `json:"detail"`
+
+	// columns holds the cell text per table column while continuation lines
+	// are read; filled tracks whether the last fragment filled its cell.
+	columns []string
+	filled  []bool
+}
+
+type StackDiff struct {
+	Stack     string           `json:"stack"`
+	Added     int              `json:"added"`
+	Modified  int              `json:"modified"`
+	Replaced  int              `json:"replaced"`
+	Removed   int              `json:"removed"`
+	Resources []ResourceChange `json:"resources"`
+	IAM       []IAMChange      `json:"iam"`
+}
+
+// Replacements returns the resources that will or may be replaced.
+func (s StackDiff) Replacements() []ResourceChange {
+	var out []ResourceChange
+	for _, rc := range s.Resources {
+		if rc.Change == ChangeReplace || rc.Change == ChangeMayReplace {
+			out = append(out, rc)
+		}
+	}
+	return out
+}
+
+// statefulTypes hold data that does not come back when the resource is
+// replaced or deleted.
+var statefulTypes = []string{
+	"AWS::DynamoDB::Table",
+	"AWS::DynamoDB::GlobalTable",
+	"AWS::Route53::HostedZone",
+	"AWS::Logs::LogGroup",
+	"AWS::S3::Bucket",
+	"AWS::RDS::DBInstance",
+	"AWS::RDS::DBCluster",
+	"AWS::Cognito::UserPool",
+	"AWS::KMS::Key",
+	"AWS::SecretsManager::Secret",
+	"AWS::SQS::Queue",
+	"AWS::EFS::FileSystem",
+}
+
+func IsStateful(resourceType string) bool {
+	return slices.Contains(statefulTypes, resourceType)
+}
+
+// Parse splits cdk diff output into one StackDiff per stack. Stacks without
+// differences are included with zero counts.
+func Parse(out string) []StackDiff {
+	var (
+		stacks  []StackDiff
+		cur     *StackDiff
+		section string
+		iamRow  bool
+	)
+	for line := range strings.SplitSeq(out, "\n") {
+		line = strings.TrimRight(line, " \r")
+		if name, ok := strings.CutPrefix(line, "Stack "); ok {
+			fields := strings.Fields(name)
+			if len(fields) == 0 {
+				continue
+			}
+			stacks = append(stacks, StackDiff{Stack: fields[0]})
+			cur = &stacks[len(stacks)-1]
+			section = ""
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+		if isSectionHeader(line) {
+			section = line
+			continue
+		}
+		switch section {
+		case "Resources":
+			if rc, ok := parseResource(line); ok {
+				cur.add(rc)
+			}
+		case "IAM Statement Changes", "IAM Policy Changes":
+			iamRow = parseIAMRow(cur, line, iamRow)
+		}
+	}
+	return stacks
+}
+
+func isSectionHeader(line string) bool {
+	switch line {
+	case "Resources", "Parameters", "Outputs", "Conditions", "Mappings", "Metadata",
+		"Other Changes", "Unknown", "IAM Statement Changes", "IAM Policy Changes",
+		"Security Group Changes":
+		return true
+	default:
+		return false
+	}
+}
+
+// parseResource reads a resource header such as
+// "[~] AWS::DynamoDB::Table Table TableCD117FA1 replace". Property lines are
+// indented and ignored.
+func parseResource(line string) (ResourceChange, bool) {
+	if len(line) < 4 || line[0] != '[' || line[2] != ']' {
+		return ResourceChange{}, false
+	}
+	fields := strings.Fields(line[3:])
+	if len(fields) < 2 {
+		return ResourceChange{}, false
+	}
+
+	rc := ResourceChange{Type: fields[0]}
+	rest := fields[1:]
+	impact := ""
+	switch {
+	case len(rest) > 3 && strings.Join(rest[len(rest)-3:], " ") == "may be replaced":
+		impact = "may be replaced"
+		rest = rest[:len(rest)-3]
+	case slices.Contains([]string{"replace", "destroy", "orphan"}, rest[len(rest)-1]) && len(rest) > 1:
+		impact = rest[len(rest)-1]
+		rest = rest[:len(rest)-1]
+	}
+	rc.LogicalID = rest[len(rest)-1]
+	rc.Path = strings.Join(rest[:len(rest)-1], " ")
+
+	switch line[1] {
+	case '+':
+		rc.Change = ChangeAdd
+	case '-':
+		rc.Change = ChangeRemove
+		if impact == "orphan" {
+			rc.Change = ChangeOrphan
+		}
+	case '~':
+		switch impact {
+		case "replace":
+			rc.Change = ChangeReplace
+		case "may be replaced":
+			rc.Change = ChangeMayReplace
+		default:
+			rc.Change = ChangeModify
+		}
+	default:
+		return ResourceChange{}, false
+	}
+	rc.Stateful = IsStateful(rc.Type)
+	return rc, true
+}
+
+func (s *StackDiff) add(rc ResourceChange) {
+	switch rc.Change {
+	case ChangeAdd:
+		s.Added++
+	case ChangeModify, ChangeMayReplace:
+		s.Modified++
+	case ChangeReplace:
+		s.Replaced++
+	case ChangeRemove, ChangeOrphan:
+		s.Removed++
+	}
+	s.Resources = append(s.Resources, rc)
+}
+
+// parseIAMRow reads a line of the boxed IAM tables and reports whether it
+// belongs to an open change row. Cells wrap onto continuation lines with an
+// empty change column; each continuation cell extends the same column of the
+// open change. Border lines close it, which keeps the header row from being
+// mistaken for a continuation.
+func parseIAMRow(s *StackDiff, line string, open bool) bool {
+	if !strings.HasPrefix(line, "│") {
+		return false
+	}
+	parts := strings.Split(line, "│")
+	if len(parts) < 4 {
+		return false
+	}
+	// The first part precedes the opening border and the last follows the
+	// closing one; parts[1] is the change column.
+	cells := parts[2 : len(parts)-1]
+
+	var change Change
+	switch strings.TrimSpace(parts[1]) {
+	case "+":
+		change = ChangeAdd
+	case "-":
+		change = ChangeRemove
+	case "":
+		if open {
+			s.IAM[len(s.IAM)-1].extend(cells)
+		}
+		return open
+	default:
+		return false
+	}
+	c := IAMChange{Change: change, columns: make([]string, len(cells)), filled: make([]bool, len(cells))}
+	c.extend(cells)
+	s.IAM = append(s.IAM, c)
+	return true
+}
+
+// extend appends a line of cells to the columns of c. A fragment that fills
+// its cell was broken mid-word, so the next fragment joins it directly;
+// otherwise the table wrapped at a space.
+func (c *IAMChange) extend(cells []string) {
+	for i, cell := range cells {
+		if i >= len(c.columns) {
+			break
+		}
+		text := strings.TrimSpace(cell)
+		if text == "" {
+			continue
+		}
+		switch {
+		case c.columns[i] == "":
+			c.columns[i] = text
+		case c.filled[i]:
+			c.columns[i] += text
+		default:
+			c.columns[i] += " " + text
+		}
+		// Cells are padded with one space on either side.
+		c.filled[i] = utf8.RuneCountInString(text) >= utf8.RuneCountInString(cell)-2
+	}
+
+	var detail []string
+	for _, col := range c.columns {
+		if col != "" {
+			detail = append(detail, col)
+		}
+	}
+	c.Detail = strings.Join(detail, " ")
+}
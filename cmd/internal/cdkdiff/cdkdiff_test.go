@@ -0,0 +1,115 @@
+package cdkdiff_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/basewarphq/bw/cmd/internal/cdkdiff"
+)
+
+func parseFixture(t *testing.T) []cdkdiff.StackDiff {
+	t.Helper()
+	data, err := os.ReadFile("testdata/diff.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cdkdiff.Parse(string(data))
+}
+
+func TestParse_Counts(t *testing.T) {
+	t.Parallel()
+	stacks := parseFixture(t)
+	if len(stacks) != 2 {
+		t.Fatalf("got %d stacks, want 2", len(stacks))
+	}
+
+	shared := stacks[0]
+	if shared.Stack != "bwappEucShared" || len(shared.Resources) != 0 {
+		t.Errorf("shared stack should have no changes, got %+v", shared)
+	}
+
+	dev := stacks[1]
+	if dev.Stack != "bwappEucDev03" {
+		t.Errorf("got stack %q", dev.Stack)
+	}
+	if dev.Added != 2 || dev.Modified != 2 || dev.Replaced != 1 || dev.Removed != 3 {
+		t.Errorf("got added=%d modified=%d replaced=%d removed=%d, want 2/2/1/3",
+			dev.Added, dev.Modified, dev.Replaced, dev.Removed)
+	}
+}
+
+func TestParse_Resources(t *testing.T) {
+	t.Parallel()
+	dev := parseFixture(t)[1]
+
+	want := map[string]cdkdiff.Change{
+		"HandlerRoleABCD1234": cdkdiff.ChangeAdd,
+		"HandlerE1234567":     cdkdiff.ChangeAdd,
+		"OtherFn5678":         cdkdiff.ChangeModify,
+		"TableCD117FA1":       cdkdiff.ChangeReplace,
+		"LogsAAAA1111":        cdkdiff.ChangeMayReplace,
+		"ZoneBBBB2222":        cdkdiff.ChangeRemove,
+		"ArchiveCCCC3333":     cdkdiff.ChangeOrphan,
+		"OldQueue4A7E3555":    cdkdiff.ChangeRemove,
+	}
+	if len(dev.Resources) != len(want) {
+		t.Fatalf("got %d resources, want %d: %+v", len(dev.Resources), len(want), dev.Resources)
+	}
+	for _, rc := range dev.Resources {
+		if rc.Change != want[rc.LogicalID] {
+			t.Errorf("%s: got %s, want %s", rc.LogicalID, rc.Change, want[rc.LogicalID])
+		}
+	}
+
+	if rc := dev.Resources[0]; rc.Type != "AWS::IAM::Role" || rc.Path != "Handler/Role" {
+		t.Errorf("got type %q path %q", rc.Type, rc.Path)
+	}
+	if rc := dev.Resources[7]; rc.Path != "" {
+		t.Errorf("resource without a path should have none, got %q", rc.Path)
+	}
+}
+
+func TestParse_Risky(t *testing.T) {
+	t.Parallel()
+	dev := parseFixture(t)[1]
+
+	var risky []string
+	for _, rc := range dev.Resources {
+		if rc.Risky() {
+			risky = append(risky, rc.LogicalID)
+		}
+	}
+	want := []string{"TableCD117FA1", "LogsAAAA1111", "ZoneBBBB2222", "OldQueue4A7E3555"}
+	if len(risky) != len(want) {
+		t.Fatalf("got risky %v, want %v", risky, want)
+	}
+	for i := range want {
+		if risky[i] != want[i] {
+			t.Errorf("risky[%d] = %s, want %s", i, risky[i], want[i])
+		}
+	}
+
+	if got := len(dev.Replacements()); got != 2 {
+		t.Errorf("got %d replacements, want 2", got)
+	}
+}
+
+func TestParse_IAM(t *testing.T) {
+	t.Parallel()
+	dev := parseFixture(t)[1]
+
+	if len(dev.IAM) != 3 {
+		t.Fatalf("got %d IAM changes, want 3: %+v", len(dev.IAM), dev.IAM)
+	}
+	first := dev.IAM[0]
+	if first.Change != cdkdiff.ChangeAdd {
+		t.Errorf("got change %s", first.Change)
+	}
+	wantDetail := "${Handler/Role.Arn} Allow sts:AssumeRole Service:lambda.amazonaws.com"
+	if first.Detail != wantDetail {
+		t.Errorf("got detail %q, want %q", first.Detail, wantDetail)
+	}
+	if dev.IAM[1].Change != cdkdiff.ChangeRemove {
+		t.Errorf("got change %s, want remove", dev.IAM[1].Change)
+	}
+}
@@ -0,0 +1,239 @@
+// Package cdkmanifest reads the cloud assembly cdk synth writes, so that
+// stack names, environments and dependencies come from the app itself
+// instead of being derived from naming conventions.
+package cdkmanifest
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	stackArtifactType = "aws:cloudformation:stack"
+	nestedStackType   = "AWS::CloudFormation::Stack"
+)
+
+// Assembly is a synthesized cloud assembly. Nested assemblies (CDK stages)
+// are not read.
+type Assembly struct {
+	Dir    string
+	Stacks []*Stack
+}
+
+type Stack struct {
+	// ID is the artifact ID, which cdk accepts as a stack selector.
+	ID   string
+	Name string
+	// Account and Region are empty for environment-agnostic stacks.
+	Account      string
+	Region       string
+	TemplateFile string
+	// Dependencies are the IDs of the stacks this one depends on.
+	Dependencies []string
+	// Outputs are the output keys the template declares, sorted.
+	Outputs       []string
+	ResourceCount int
+	// NestedTemplates are the template files of the stack's nested stacks,
+	// including nested stacks of nested stacks.
+	NestedTemplates []string
+}
+
+type manifestJSON struct {
+	Artifacts map[string]struct {
+		Type         string   `json:"type"`
+		Environment  string   `json:"environment"`
+		Dependencies []string `json:"dependencies"`
+		Properties   struct {
+			TemplateFile string `json:"templateFile"`
+			StackName    string `json:"stackName"`
+		} `json:"properties"`
+	} `json:"artifacts"`
+}
+
+type templateJSON struct {
+	Resources map[string]resourceJSON    `json:"Resources"`
+	Outputs   map[string]json.RawMessage `json:"Outputs"`
+}
+
+type resourceJSON struct {
+	Type     string `json:"Type"`
+	Metadata struct {
+		// AssetPath is where cdk put a nested stack's template, relative to
+		// the assembly directory.
+		AssetPath string `json:"aws:asset:path"`
+	} `json:"Metadata"`
+}
+
+// Load reads manifest.json and the stack templates of the assembly in dir.
+func Load(dir string) (*Assembly, error) {
+	manifestPath := filepath.Join(dir, "manifest.json")
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %s", manifestPath)
+	}
+	var m manifestJSON
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, errors.Wrapf(err, "parsing %s", manifestPath)
+	}
+
+	asm := &Assembly{Dir: dir}
+	for id, art := range m.Artifacts {
+		if art.Type != stackArtifactType {
+			continue
+		}
+		s := &Stack{
+			ID:           id,
+			Name:         art.Properties.StackName,
+			TemplateFile: filepath.Join(dir, art.Properties.TemplateFile),
+		}
+		if s.Name == "" {
+			s.Name = id
+		}
+		s.Account, s.Region = parseEnvironment(art.Environment)
+		for _, dep := range art.Dependencies {
+			if m.Artifacts[dep].Type == stackArtifactType {
+				s.Dependencies = append(s.Dependencies, dep)
+			}
+		}
+		sort.Strings(s.Dependencies)
+
+		if err := s.readTemplate(); err != nil {
+			return nil, err
+		}
+		asm.Stacks = append(asm.Stacks, s)
+	}
+	sort.Slice(asm.Stacks, func(i, j int) bool { return asm.Stacks[i].ID < asm.Stacks[j].ID })
+	return asm, nil
+}
+
+func (s *Stack) readTemplate() error {
+	tmpl, err := readTemplateFile(s.TemplateFile)
+	if err != nil {
+		return errors.Wrapf(err, "template of %s", s.ID)
+	}
+	s.ResourceCount = len(tmpl.Resources)
+	for key := range tmpl.Outputs {
+		s.Outputs = append(s.Outputs, key)
+	}
+	sort.Strings(s.Outputs)
+
+	s.NestedTemplates, err = nestedTemplates(filepath.Dir(s.TemplateFile), tmpl)
+	if err != nil {
+		return errors.Wrapf(err, "nested templates of %s", s.ID)
+	}
+	return nil
+}
+
+func readTemplateFile(path string) (*templateJSON, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %s", path)
+	}
+	var tmpl templateJSON
+	if err := json.Unmarshal(data, &tmpl); err != nil {
+		return nil, errors.Wrapf(err, "parsing %s", path)
+	}
+	return &tmpl, nil
+}
+
+// nestedTemplates returns the template files of the nested stacks in tmpl
+// and, depth first, those of their own nested stacks.
+func nestedTemplates(dir string, tmpl *templateJSON) ([]string, error) {
+	ids := make([]string, 0, len(tmpl.Resources))
+	for id := range tmpl.Resources {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var paths []string
+	for _, id := range ids {
+		res := tmpl.Resources[id]
+		if res.Type != nestedStackType || res.Metadata.AssetPath == "" {
+			continue
+		}
+		path := filepath.Join(dir, res.Metadata.AssetPath)
+		nested, err := readTemplateFile(path)
+		if err != nil {
+			return nil, err
+		}
+		inner, err := nestedTemplates(dir, nested)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+		paths = append(paths, inner...)
+	}
+	return paths, nil
+}
+
+// parseEnvironment splits aws://{account}/{region}, treating the
+// unknown-account and unknown-region placeholders as empty.
+func parseEnvironment(env string) (account, region string) {
+	rest, ok := strings.CutPrefix(env, "aws://")
+	if !ok {
+		return "", ""
+	}
+	account, region, _ = strings.Cut(rest, "/")
+	if account == "unknown-account" {
+		account = ""
+	}
+	if region == "unknown-region" {
+		region = ""
+	}
+	return account, region
+}
+
+// Stack returns the stack with the given CloudFormation name.
+func (a *Assembly) Stack(name string) (*Stack, bool) {
+	for _, s := range a.Stacks {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+// DeployOrder returns the stacks with every stack after its dependencies,
+// ordered by ID where dependencies leave a choice.
+func (a *Assembly) DeployOrder() ([]*Stack, error) {
+	byID := make(map[string]*Stack, len(a.Stacks))
+	for _, s := range a.Stacks {
+		byID[s.ID] = s
+	}
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int, len(a.Stacks))
+	ordered := make([]*Stack, 0, len(a.Stacks))
+	var visit func(s *Stack) error
+	visit = func(s *Stack) error {
+		switch state[s.ID] {
+		case done:
+			return nil
+		case visiting:
+			return errors.Newf("dependency cycle through stack %s", s.ID)
+		}
+		state[s.ID] = visiting
+		for _, dep := range s.Dependencies {
+			if err := visit(byID[dep]); err != nil {
+				return err
+			}
+		}
+		state[s.ID] = done
+		ordered = append(ordered, s)
+		return nil
+	}
+	for _, s := range a.Stacks {
+		if err := visit(s); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
@@ -0,0 +1,68 @@
+package cdkmanifest_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/basewarphq/bw/cmd/internal/cdkmanifest"
+)
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+	asm, err := cdkmanifest.Load("testdata/cdk.out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(asm.Stacks) != 3 {
+		t.Fatalf("got %d stacks, want 3", len(asm.Stacks))
+	}
+
+	prod, ok := asm.Stack("bwappEuc1Prod")
+	if !ok {
+		t.Fatal("bwappEuc1Prod not found")
+	}
+	if prod.Region != "eu-central-1" || prod.Account != "" {
+		t.Errorf("environment: got %q/%q", prod.Account, prod.Region)
+	}
+	if !slices.Equal(prod.Dependencies, []string{"bwappEuc1Shared", "bwappEuw1Shared"}) {
+		t.Errorf("dependencies: got %v", prod.Dependencies)
+	}
+	if !slices.Equal(prod.Outputs, []string{"ApiLogGroup", "GatewayURL"}) || prod.ResourceCount != 2 {
+		t.Errorf("template: got outputs %v and %d resources", prod.Outputs, prod.ResourceCount)
+	}
+
+	shared, _ := asm.Stack("bwappEuw1Shared")
+	if shared.Account != "111111111111" || shared.Region != "eu-west-1" {
+		t.Errorf("environment: got %q/%q", shared.Account, shared.Region)
+	}
+	if !slices.Equal(shared.Dependencies, []string{"bwappEuc1Shared"}) {
+		t.Errorf("asset dependencies should be dropped, got %v", shared.Dependencies)
+	}
+	wantNested := []string{
+		"testdata/cdk.out/bwappEuw1SharedNested1234.nested.template.json",
+		"testdata/cdk.out/bwappEuw1SharedInner5678.nested.template.json",
+	}
+	if !slices.Equal(shared.NestedTemplates, wantNested) {
+		t.Errorf("nested templates: got %v, want %v", shared.NestedTemplates, wantNested)
+	}
+}
+
+func TestDeployOrder(t *testing.T) {
+	t.Parallel()
+	asm, err := cdkmanifest.Load("testdata/cdk.out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ordered, err := asm.DeployOrder()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, s := range ordered {
+		ids = append(ids, s.ID)
+	}
+	want := []string{"bwappEuc1Shared", "bwappEuw1Shared", "bwappEuc1Prod"}
+	if !slices.Equal(ids, want) {
+		t.Errorf("got %v, want %v", ids, want)
+	}
+}
@@ -32,3 +32,29 @@ func Deploy(ctx context.Context, dir, profile, stackName, templatePath string, p
 	}
 	return cmdexec.Run(ctx, dir, "aws", args...)
 }
+
+func ExecuteChangeSet(ctx context.Context, region, profile, changeSetID string) error {
+	args := []string{
+		"cloudformation", "execute-change-set",
+		"--no-cli-pager",
+		"--region", region,
+		"--change-set-name", changeSetID,
+	}
+	if profile != "" {
+		args = append(args, "--profile", profile)
+	}
+	return cmdexec.Run(ctx, "/", "aws", args...)
+}
+
+func DeleteChangeSet(ctx context.Context, region, profile, changeSetID string) error {
+	args := []string{
+		"cloudformation", "delete-change-set",
+		"--no-cli-pager",
+		"--region", region,
+		"--change-set-name", changeSetID,
+	}
+	if profile != "" {
+		args = append(args, "--profile", profile)
+	}
+	return cmdexec.Run(ctx, "/", "aws", args...)
+}
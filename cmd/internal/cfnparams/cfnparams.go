@@ -1,38 +1,142 @@
 package cfnparams
 
 import (
+	"context"
+	"fmt"
 	"regexp"
+	"sort"
+	"strings"
 
 	"github.com/cockroachdb/errors"
 )
 
 var placeholderRe = regexp.MustCompile(`\{\{([^}]+)\}\}`)
 
+// ErrNotFound marks a lookup that found no value, as opposed to one that
+// failed. Only missing values fall back to a placeholder's default.
+var ErrNotFound = errors.New("value not found")
+
+// Sources are where placeholders get their values from:
+//
+//	{{key}}                 Context, the cdk.context.json values
+//	{{env:VAR}}             LookupEnv
+//	{{ssm:/path}}           GetParameter
+//	{{op://vault/item/f}}   ReadSecret, given the whole op:// reference
+//	{{account}}, {{region}} Account and Region
+//
+// Any placeholder can carry a default after a pipe: {{key|default}}. Lookups
+// that are nil treat every value as missing.
+type Sources struct {
+	Context      map[string]string
+	LookupEnv    func(name string) (string, bool)
+	GetParameter func(ctx context.Context, name string) (string, error)
+	ReadSecret   func(ctx context.Context, ref string) (string, error)
+	Account      func(ctx context.Context) (string, error)
+	Region       string
+}
+
+// Resolve substitutes placeholders with context values only.
 func Resolve(raw map[string]string, ctxValues map[string]string) (map[string]string, error) {
+	return ResolveSources(context.Background(), raw, Sources{Context: ctxValues})
+}
+
+// ResolveSources substitutes the placeholders of every parameter. Each
+// distinct placeholder is looked up once, and all missing or failed lookups
+// are reported together.
+func ResolveSources(ctx context.Context, raw map[string]string, src Sources) (map[string]string, error) {
+	names := make([]string, 0, len(raw))
+	for k := range raw {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	lookups := map[string]lookupResult{}
+	var problems []string
 	resolved := make(map[string]string, len(raw))
-	for k, v := range raw {
-		val, err := interpolate(v, ctxValues)
-		if err != nil {
-			return nil, errors.Wrapf(err, "parameter %q", k)
-		}
-		resolved[k] = val
+	for _, name := range names {
+		resolved[name] = placeholderRe.ReplaceAllStringFunc(raw[name], func(match string) string {
+			expr := placeholderRe.FindStringSubmatch(match)[1]
+			key, def, hasDefault := strings.Cut(expr, "|")
+			key = strings.TrimSpace(key)
+
+			res, ok := lookups[key]
+			if !ok {
+				res = src.lookup(ctx, key)
+				lookups[key] = res
+			}
+			switch {
+			case res.err == nil:
+				return res.value
+			case errors.Is(res.err, ErrNotFound) && hasDefault:
+				return def
+			default:
+				problems = append(problems, fmt.Sprintf("parameter %q: %s", name, res.err))
+				return match
+			}
+		})
+	}
+	if len(problems) > 0 {
+		return nil, errors.Newf("unresolved parameters:\n  %s", strings.Join(problems, "\n  "))
 	}
 	return resolved, nil
 }
 
-func interpolate(val string, ctxValues map[string]string) (string, error) {
-	var resolveErr error
-	result := placeholderRe.ReplaceAllStringFunc(val, func(match string) string {
-		key := placeholderRe.FindStringSubmatch(match)[1]
-		v, ok := ctxValues[key]
-		if !ok {
-			resolveErr = errors.Newf("unknown context key %q", key)
-			return match
+type lookupResult struct {
+	value string
+	err   error
+}
+
+func (s Sources) lookup(ctx context.Context, key string) lookupResult {
+	notFound := func(format string, args ...any) lookupResult {
+		return lookupResult{err: errors.Mark(errors.Newf(format, args...), ErrNotFound)}
+	}
+	fetched := func(value string, err error, what string) lookupResult {
+		switch {
+		case errors.Is(err, ErrNotFound):
+			return notFound("%s not found", what)
+		case err != nil:
+			return lookupResult{err: errors.Wrapf(err, "reading %s", what)}
+		}
+		return lookupResult{value: value}
+	}
+
+	switch {
+	case strings.HasPrefix(key, "env:"):
+		name := strings.TrimPrefix(key, "env:")
+		if s.LookupEnv != nil {
+			if v, ok := s.LookupEnv(name); ok {
+				return lookupResult{value: v}
+			}
+		}
+		return notFound("environment variable %s is not set", name)
+	case strings.HasPrefix(key, "ssm:"):
+		name := strings.TrimPrefix(key, "ssm:")
+		if s.GetParameter == nil {
+			return notFound("SSM parameter %s not found", name)
 		}
-		return v
-	})
-	if resolveErr != nil {
-		return "", resolveErr
+		v, err := s.GetParameter(ctx, name)
+		return fetched(v, err, "SSM parameter "+name)
+	case strings.HasPrefix(key, "op://"):
+		if s.ReadSecret == nil {
+			return notFound("1Password secret %s not found", key)
+		}
+		v, err := s.ReadSecret(ctx, key)
+		return fetched(v, err, "1Password secret "+key)
+	case key == "account":
+		if s.Account == nil {
+			return notFound("AWS account is unknown")
+		}
+		v, err := s.Account(ctx)
+		return fetched(v, err, "AWS account")
+	case key == "region":
+		if s.Region == "" {
+			return notFound("AWS region is unknown")
+		}
+		return lookupResult{value: s.Region}
+	}
+
+	if v, ok := s.Context[key]; ok {
+		return lookupResult{value: v}
 	}
-	return result, nil
+	return notFound("unknown context key %q", key)
 }
@@ -1,10 +1,12 @@
 package cfnparams_test
 
 import (
+	"context"
 	"strings"
 	"testing"
 
 	"github.com/basewarphq/bw/cmd/internal/cfnparams"
+	"github.com/cockroachdb/errors"
 )
 
 func TestResolve_StaticValues(t *testing.T) {
@@ -88,3 +90,94 @@ func TestResolve_EmptyMap(t *testing.T) {
 		t.Errorf("expected empty map, got %v", got)
 	}
 }
+
+func testSources() cfnparams.Sources {
+	return cfnparams.Sources{
+		Context: map[string]string{"qualifier": "bwapp"},
+		LookupEnv: func(name string) (string, bool) {
+			if name == "GITHUB_ORG" {
+				return "basewarphq", true
+			}
+			return "", false
+		},
+		GetParameter: func(_ context.Context, name string) (string, error) {
+			switch name {
+			case "/bw/repo":
+				return "basewarphq/bw", nil
+			case "/bw/forbidden":
+				return "", errors.New("AccessDenied")
+			}
+			return "", cfnparams.ErrNotFound
+		},
+		ReadSecret: func(_ context.Context, ref string) (string, error) {
+			if ref == "op://infra/github/token" {
+				return "s3cret", nil
+			}
+			return "", cfnparams.ErrNotFound
+		},
+		Account: func(context.Context) (string, error) { return "111111111111", nil },
+		Region:  "eu-central-1",
+	}
+}
+
+func TestResolveSources_Prefixes(t *testing.T) {
+	t.Parallel()
+	raw := map[string]string{
+		"Org":      "{{env:GITHUB_ORG}}",
+		"Repo":     "{{ssm:/bw/repo}}",
+		"Token":    "{{op://infra/github/token}}",
+		"Location": "{{account}}/{{region}}",
+		"Name":     "{{qualifier}}-roles",
+	}
+	got, err := cfnparams.ResolveSources(context.Background(), raw, testSources())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"Org":      "basewarphq",
+		"Repo":     "basewarphq/bw",
+		"Token":    "s3cret",
+		"Location": "111111111111/eu-central-1",
+		"Name":     "bwapp-roles",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s: got %q, want %q", k, got[k], v)
+		}
+	}
+}
+
+func TestResolveSources_Defaults(t *testing.T) {
+	t.Parallel()
+	raw := map[string]string{
+		"Branch": "{{env:DEPLOY_BRANCH|main}}",
+		"Empty":  "{{ssm:/bw/missing|}}",
+		"Set":    "{{qualifier|fallback}}",
+	}
+	got, err := cfnparams.ResolveSources(context.Background(), raw, testSources())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["Branch"] != "main" || got["Empty"] != "" || got["Set"] != "bwapp" {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestResolveSources_ReportsAllMissing(t *testing.T) {
+	t.Parallel()
+	raw := map[string]string{
+		"A": "{{env:NOPE}}",
+		"B": "{{ssm:/bw/missing}}",
+		"C": "{{unknown}}",
+		"D": "{{ssm:/bw/forbidden|default}}",
+	}
+	_, err := cfnparams.ResolveSources(context.Background(), raw, testSources())
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, want := range []string{"NOPE", "/bw/missing", "unknown", "AccessDenied"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error should mention %q, got: %v", want, err)
+		}
+	}
+}
@@ -7,9 +7,31 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-const ruleID = "CleanupDevSlotClaims"
+// DevSlotRuleID is the Id of the staging bucket lifecycle rule that expires
+// dev slot locks.
+const DevSlotRuleID = "CleanupDevSlotClaims"
+
+// DevSlotLockTagKey and DevSlotLockTagValue tag the dev slot lock objects.
+// The lifecycle rule only expires tagged objects, so the slot history that
+// shares the dev-slots/ prefix is kept.
+const (
+	DevSlotLockTagKey   = "bw-dev-slot"
+	DevSlotLockTagValue = "lock"
+)
 
-func AddDevSlotLifecycle(templateYAML []byte, expirationDays int) ([]byte, error) {
+// DefaultDevSlotExpirationDays is how long a dev slot lock survives without
+// being touched before the bucket lifecycle rule deletes it.
+const DefaultDevSlotExpirationDays = 7
+
+// Patch edits the root mapping of a bootstrap template in place. Patches are
+// idempotent so re-bootstrapping with an already patched template is safe.
+type Patch interface {
+	Name() string
+	Apply(root *yaml.Node) error
+}
+
+// Apply parses a template, runs the patches in order and returns the result.
+func Apply(templateYAML []byte, patches ...Patch) ([]byte, error) {
 	var doc yaml.Node
 	if err := yaml.Unmarshal(templateYAML, &doc); err != nil {
 		return nil, errors.Wrap(err, "parsing template YAML")
@@ -19,48 +41,104 @@ func AddDevSlotLifecycle(templateYAML []byte, expirationDays int) ([]byte, error
 		return nil, errors.New("invalid YAML document")
 	}
 
-	resources, err := mappingValue(doc.Content[0], "Resources")
+	for _, p := range patches {
+		if err := p.Apply(doc.Content[0]); err != nil {
+			return nil, errors.Wrapf(err, "patch %s", p.Name())
+		}
+	}
+
+	out, err := yaml.Marshal(&doc)
 	if err != nil {
-		return nil, err
+		return nil, errors.Wrap(err, "marshaling patched template")
+	}
+	return out, nil
+}
+
+// BootstrapVersion returns the value of the CdkBootstrapVersion parameter a
+// bootstrap template deploys.
+func BootstrapVersion(templateYAML []byte) (int, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(templateYAML, &doc); err != nil {
+		return 0, errors.Wrap(err, "parsing template YAML")
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return 0, errors.New("invalid YAML document")
 	}
 
-	bucket, err := mappingValue(resources, "StagingBucket")
+	props, err := resourceProperties(doc.Content[0], "CdkBootstrapVersion")
 	if err != nil {
-		return nil, errors.Wrap(err, "in Resources")
+		return 0, err
 	}
+	value, err := mappingValue(props, "Value")
+	if err != nil {
+		return 0, errors.Wrap(err, "in CdkBootstrapVersion.Properties")
+	}
+	version, err := strconv.Atoi(value.Value)
+	if err != nil {
+		return 0, errors.Newf("CdkBootstrapVersion value %q is not a number", value.Value)
+	}
+	return version, nil
+}
 
-	props, err := mappingValue(bucket, "Properties")
+func AddDevSlotLifecycle(templateYAML []byte, expirationDays int) ([]byte, error) {
+	return Apply(templateYAML, DevSlotLifecycle{ExpirationDays: expirationDays})
+}
+
+// DevSlotLifecycle adds a lifecycle rule to the staging bucket that expires
+// dev slot lock objects: those under dev-slots/ tagged as locks.
+type DevSlotLifecycle struct {
+	ExpirationDays int
+}
+
+func (DevSlotLifecycle) Name() string { return "dev-slot-lifecycle" }
+
+func (p DevSlotLifecycle) Apply(root *yaml.Node) error {
+	props, err := resourceProperties(root, "StagingBucket")
 	if err != nil {
-		return nil, errors.Wrap(err, "in StagingBucket")
+		return err
 	}
 
 	lifecycleCfg, err := mappingValue(props, "LifecycleConfiguration")
 	if err != nil {
-		return nil, errors.Wrap(err, "in StagingBucket.Properties")
+		return errors.Wrap(err, "in StagingBucket.Properties")
 	}
 
 	rules, err := mappingValue(lifecycleCfg, "Rules")
 	if err != nil {
-		return nil, errors.Wrap(err, "in StagingBucket.Properties.LifecycleConfiguration")
+		return errors.Wrap(err, "in StagingBucket.Properties.LifecycleConfiguration")
 	}
 
 	if rules.Kind != yaml.SequenceNode {
-		return nil, errors.New("LifecycleConfiguration.Rules is not a sequence")
+		return errors.New("LifecycleConfiguration.Rules is not a sequence")
 	}
 
-	newRule := buildRuleNode(expirationDays)
+	newRule := buildRuleNode(p.ExpirationDays)
 
-	if idx := findRuleByID(rules, ruleID); idx >= 0 {
+	if idx := findRuleByID(rules, DevSlotRuleID); idx >= 0 {
 		rules.Content[idx] = newRule
 	} else {
 		rules.Content = append(rules.Content, newRule)
 	}
+	return nil
+}
 
-	out, err := yaml.Marshal(&doc)
+// resourceProperties returns Resources.<name>.Properties of the template.
+func resourceProperties(root *yaml.Node, name string) (*yaml.Node, error) {
+	resources, err := mappingValue(root, "Resources")
 	if err != nil {
-		return nil, errors.Wrap(err, "marshaling patched template")
+		return nil, err
 	}
-	return out, nil
+
+	resource, err := mappingValue(resources, name)
+	if err != nil {
+		return nil, errors.Wrap(err, "in Resources")
+	}
+
+	props, err := mappingValue(resource, "Properties")
+	if err != nil {
+		return nil, errors.Wrapf(err, "in %s", name)
+	}
+	return props, nil
 }
 
 func mappingValue(node *yaml.Node, key string) (*yaml.Node, error) {
@@ -75,6 +153,22 @@ func mappingValue(node *yaml.Node, key string) (*yaml.Node, error) {
 	return nil, errors.Newf("key %q not found", key)
 }
 
+// setMappingValue replaces the value of key, or appends the key when the
+// mapping does not have it yet.
+func setMappingValue(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, scalar(key), value)
+}
+
+func scalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+}
+
 func findRuleByID(rules *yaml.Node, id string) int {
 	for i, rule := range rules.Content {
 		if rule.Kind != yaml.MappingNode {
@@ -94,11 +188,19 @@ func buildRuleNode(expirationDays int) *yaml.Node {
 		Kind: yaml.MappingNode,
 		Content: []*yaml.Node{
 			{Kind: yaml.ScalarNode, Value: "Id"},
-			{Kind: yaml.ScalarNode, Value: ruleID},
+			{Kind: yaml.ScalarNode, Value: DevSlotRuleID},
 			{Kind: yaml.ScalarNode, Value: "Status"},
 			{Kind: yaml.ScalarNode, Value: "Enabled"},
 			{Kind: yaml.ScalarNode, Value: "Prefix"},
 			{Kind: yaml.ScalarNode, Value: "dev-slots/"},
+			{Kind: yaml.ScalarNode, Value: "TagFilters"},
+			{Kind: yaml.SequenceNode, Content: []*yaml.Node{{
+				Kind: yaml.MappingNode,
+				Content: []*yaml.Node{
+					scalar("Key"), scalar(DevSlotLockTagKey),
+					scalar("Value"), scalar(DevSlotLockTagValue),
+				},
+			}}},
 			{Kind: yaml.ScalarNode, Value: "ExpirationInDays"},
 			{Kind: yaml.ScalarNode, Value: strconv.Itoa(expirationDays), Tag: "!!int"},
 		},
@@ -197,6 +197,9 @@ func TestAddDevSlotLifecycle_RealTemplate(t *testing.T) {
 	if !strings.Contains(added, "dev-slots/") {
 		t.Error("added rule should have Prefix dev-slots/")
 	}
+	if !strings.Contains(added, "TagFilters") || !strings.Contains(added, cfnpatch.DevSlotLockTagKey) {
+		t.Error("added rule should only expire objects tagged as locks")
+	}
 	if !strings.Contains(added, "ExpirationInDays") {
 		t.Error("added rule should have ExpirationInDays")
 	}
@@ -215,3 +218,18 @@ func findKey(t *testing.T, node *yaml.Node, key string) *yaml.Node {
 	t.Fatalf("key %q not found", key)
 	return nil
 }
+
+func TestBootstrapVersion(t *testing.T) {
+	t.Parallel()
+	version, err := cfnpatch.BootstrapVersion([]byte(bootstrapTemplateSnapshot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 30 {
+		t.Errorf("got %d, want 30", version)
+	}
+
+	if _, err := cfnpatch.BootstrapVersion([]byte(templateWithRules)); err == nil {
+		t.Error("expected error for template without CdkBootstrapVersion")
+	}
+}
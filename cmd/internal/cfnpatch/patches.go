@@ -0,0 +1,156 @@
+package cfnpatch
+
+import (
+	"encoding/json"
+	"slices"
+	"sort"
+
+	"github.com/cockroachdb/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// BucketAccessLogging sends S3 server access logs of the staging bucket to
+// another bucket.
+type BucketAccessLogging struct {
+	TargetBucket string
+	Prefix       string
+}
+
+func (BucketAccessLogging) Name() string { return "bucket-access-logging" }
+
+func (p BucketAccessLogging) Apply(root *yaml.Node) error {
+	props, err := resourceProperties(root, "StagingBucket")
+	if err != nil {
+		return err
+	}
+	logging := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+		scalar("DestinationBucketName"), scalar(p.TargetBucket),
+	}}
+	if p.Prefix != "" {
+		logging.Content = append(logging.Content, scalar("LogFilePrefix"), scalar(p.Prefix))
+	}
+	setMappingValue(props, "LoggingConfiguration", logging)
+	return nil
+}
+
+// KMSKeyRotation enables yearly rotation of the key that encrypts the
+// staging bucket, when the bootstrap stack creates one.
+type KMSKeyRotation struct{}
+
+func (KMSKeyRotation) Name() string { return "kms-key-rotation" }
+
+func (KMSKeyRotation) Apply(root *yaml.Node) error {
+	props, err := resourceProperties(root, "FileAssetsBucketEncryptionKey")
+	if err != nil {
+		return err
+	}
+	setMappingValue(props, "EnableKeyRotation", &yaml.Node{Kind: yaml.ScalarNode, Value: "true", Tag: "!!bool"})
+	return nil
+}
+
+// taggableTypes take Tags as a list of Key/Value pairs.
+var taggableTypes = []string{
+	"AWS::S3::Bucket",
+	"AWS::KMS::Key",
+	"AWS::ECR::Repository",
+	"AWS::IAM::Role",
+}
+
+// Tags adds tags to every bootstrap resource that supports them. Existing
+// tags with the same key are overwritten.
+type Tags struct {
+	Tags map[string]string
+}
+
+func (Tags) Name() string { return "tags" }
+
+func (p Tags) Apply(root *yaml.Node) error {
+	resources, err := mappingValue(root, "Resources")
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(p.Tags))
+	for k := range p.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for i := 0; i < len(resources.Content)-1; i += 2 {
+		resource := resources.Content[i+1]
+		typ, err := mappingValue(resource, "Type")
+		if err != nil {
+			continue
+		}
+		switch {
+		case slices.Contains(taggableTypes, typ.Value):
+			props, err := mappingValue(resource, "Properties")
+			if err != nil {
+				return errors.Wrapf(err, "in %s", resources.Content[i].Value)
+			}
+			tagList(props, keys, p.Tags)
+		case typ.Value == "AWS::SSM::Parameter":
+			// SSM parameters take Tags as a plain map.
+			props, err := mappingValue(resource, "Properties")
+			if err != nil {
+				return errors.Wrapf(err, "in %s", resources.Content[i].Value)
+			}
+			tagMap, err := mappingValue(props, "Tags")
+			if err != nil {
+				tagMap = &yaml.Node{Kind: yaml.MappingNode}
+				setMappingValue(props, "Tags", tagMap)
+			}
+			for _, k := range keys {
+				setMappingValue(tagMap, k, scalar(p.Tags[k]))
+			}
+		}
+	}
+	return nil
+}
+
+func tagList(props *yaml.Node, keys []string, tags map[string]string) {
+	list, err := mappingValue(props, "Tags")
+	if err != nil || list.Kind != yaml.SequenceNode {
+		list = &yaml.Node{Kind: yaml.SequenceNode}
+		setMappingValue(props, "Tags", list)
+	}
+	for _, k := range keys {
+		tag := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+			scalar("Key"), scalar(k),
+			scalar("Value"), scalar(tags[k]),
+		}}
+		replaced := false
+		for i, existing := range list.Content {
+			if key, err := mappingValue(existing, "Key"); err == nil && key.Value == k {
+				list.Content[i] = tag
+				replaced = true
+			}
+		}
+		if !replaced {
+			list.Content = append(list.Content, tag)
+		}
+	}
+}
+
+// ECRLifecycle replaces the lifecycle policy of the container assets
+// repository with a custom one.
+type ECRLifecycle struct {
+	PolicyText string
+}
+
+func (ECRLifecycle) Name() string { return "ecr-lifecycle" }
+
+func (p ECRLifecycle) Apply(root *yaml.Node) error {
+	if !json.Valid([]byte(p.PolicyText)) {
+		return errors.New("lifecycle policy is not valid JSON")
+	}
+	props, err := resourceProperties(root, "ContainerAssetsRepository")
+	if err != nil {
+		return err
+	}
+	policy := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+		scalar("LifecyclePolicyText"), {Kind: yaml.ScalarNode, Value: p.PolicyText, Style: yaml.LiteralStyle},
+	}}
+	setMappingValue(props, "LifecyclePolicy", policy)
+	return nil
+}
@@ -0,0 +1,159 @@
+package cfnpatch_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/basewarphq/bw/cmd/internal/cfnpatch"
+	"gopkg.in/yaml.v3"
+)
+
+// applySnapshot patches the bootstrap snapshot and returns its Resources.
+func applySnapshot(t *testing.T, patches ...cfnpatch.Patch) *yaml.Node {
+	t.Helper()
+	out, err := cfnpatch.Apply([]byte(bootstrapTemplateSnapshot), patches...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("parsing patched: %v", err)
+	}
+	return findKey(t, doc.Content[0], "Resources")
+}
+
+// assertOnlyChanged fails when a resource not listed in changed differs
+// from the unpatched snapshot.
+func assertOnlyChanged(t *testing.T, patched *yaml.Node, changed ...string) {
+	t.Helper()
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(bootstrapTemplateSnapshot), &doc); err != nil {
+		t.Fatalf("parsing original: %v", err)
+	}
+	orig := findKey(t, doc.Content[0], "Resources")
+	for i := 0; i < len(orig.Content)-1; i += 2 {
+		key := orig.Content[i].Value
+		if slices.Contains(changed, key) {
+			continue
+		}
+		origYAML, _ := yaml.Marshal(orig.Content[i+1])
+		patchedYAML, _ := yaml.Marshal(findKey(t, patched, key))
+		if string(origYAML) != string(patchedYAML) {
+			t.Errorf("resource %q was modified by patching", key)
+		}
+	}
+}
+
+func TestDevSlotLifecycle_Days(t *testing.T) {
+	t.Parallel()
+	resources := applySnapshot(t, cfnpatch.DevSlotLifecycle{ExpirationDays: 3})
+	assertOnlyChanged(t, resources, "StagingBucket")
+
+	props := findKey(t, findKey(t, resources, "StagingBucket"), "Properties")
+	rules := findKey(t, findKey(t, props, "LifecycleConfiguration"), "Rules")
+	added := rules.Content[len(rules.Content)-1]
+	if got := findKey(t, added, "ExpirationInDays").Value; got != "3" {
+		t.Errorf("ExpirationInDays = %s, want 3", got)
+	}
+}
+
+func TestBucketAccessLogging(t *testing.T) {
+	t.Parallel()
+	resources := applySnapshot(t, cfnpatch.BucketAccessLogging{TargetBucket: "audit-logs", Prefix: "cdk-assets/"})
+	assertOnlyChanged(t, resources, "StagingBucket")
+
+	props := findKey(t, findKey(t, resources, "StagingBucket"), "Properties")
+	logging := findKey(t, props, "LoggingConfiguration")
+	if got := findKey(t, logging, "DestinationBucketName").Value; got != "audit-logs" {
+		t.Errorf("DestinationBucketName = %q, want audit-logs", got)
+	}
+	if got := findKey(t, logging, "LogFilePrefix").Value; got != "cdk-assets/" {
+		t.Errorf("LogFilePrefix = %q, want cdk-assets/", got)
+	}
+}
+
+func TestKMSKeyRotation(t *testing.T) {
+	t.Parallel()
+	resources := applySnapshot(t, cfnpatch.KMSKeyRotation{})
+	assertOnlyChanged(t, resources, "FileAssetsBucketEncryptionKey")
+
+	props := findKey(t, findKey(t, resources, "FileAssetsBucketEncryptionKey"), "Properties")
+	if got := findKey(t, props, "EnableKeyRotation").Value; got != "true" {
+		t.Errorf("EnableKeyRotation = %s, want true", got)
+	}
+}
+
+func TestTags(t *testing.T) {
+	t.Parallel()
+	resources := applySnapshot(t, cfnpatch.Tags{Tags: map[string]string{
+		"team":                   "platform",
+		"aws-cdk:bootstrap-role": "overridden",
+	}})
+
+	for _, name := range []string{"StagingBucket", "ContainerAssetsRepository", "FileAssetsBucketEncryptionKey", "DeploymentActionRole"} {
+		props := findKey(t, findKey(t, resources, name), "Properties")
+		tags := findKey(t, props, "Tags")
+		if tags.Kind != yaml.SequenceNode {
+			t.Fatalf("%s: Tags is not a list", name)
+		}
+		values := map[string]string{}
+		for _, tag := range tags.Content {
+			values[findKey(t, tag, "Key").Value] = findKey(t, tag, "Value").Value
+		}
+		if values["team"] != "platform" {
+			t.Errorf("%s: missing team tag, got %v", name, values)
+		}
+		if name == "DeploymentActionRole" && values["aws-cdk:bootstrap-role"] != "overridden" {
+			t.Errorf("%s: existing tag not overwritten, got %v", name, values)
+		}
+	}
+
+	props := findKey(t, findKey(t, resources, "CdkBootstrapVersion"), "Properties")
+	tags := findKey(t, props, "Tags")
+	if tags.Kind != yaml.MappingNode || findKey(t, tags, "team").Value != "platform" {
+		t.Error("CdkBootstrapVersion should get tags as a map")
+	}
+}
+
+func TestECRLifecycle(t *testing.T) {
+	t.Parallel()
+	policy := `{"rules":[{"rulePriority":1,"selection":{"tagStatus":"any","countType":"imageCountMoreThan","countNumber":50},"action":{"type":"expire"}}]}`
+	resources := applySnapshot(t, cfnpatch.ECRLifecycle{PolicyText: policy})
+	assertOnlyChanged(t, resources, "ContainerAssetsRepository")
+
+	props := findKey(t, findKey(t, resources, "ContainerAssetsRepository"), "Properties")
+	text := findKey(t, findKey(t, props, "LifecyclePolicy"), "LifecyclePolicyText").Value
+	if text != policy {
+		t.Errorf("LifecyclePolicyText = %q, want %q", text, policy)
+	}
+}
+
+func TestECRLifecycle_InvalidJSON(t *testing.T) {
+	t.Parallel()
+	_, err := cfnpatch.Apply([]byte(bootstrapTemplateSnapshot), cfnpatch.ECRLifecycle{PolicyText: "{"})
+	if err == nil || !strings.Contains(err.Error(), "ecr-lifecycle") {
+		t.Fatalf("expected error naming the patch, got %v", err)
+	}
+}
+
+func TestApply_Idempotent(t *testing.T) {
+	t.Parallel()
+	patches := []cfnpatch.Patch{
+		cfnpatch.DevSlotLifecycle{ExpirationDays: 7},
+		cfnpatch.BucketAccessLogging{TargetBucket: "audit-logs"},
+		cfnpatch.KMSKeyRotation{},
+		cfnpatch.Tags{Tags: map[string]string{"team": "platform"}},
+	}
+	once, err := cfnpatch.Apply([]byte(bootstrapTemplateSnapshot), patches...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	twice, err := cfnpatch.Apply(once, patches...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(once) != string(twice) {
+		t.Error("applying the patches twice changed the template")
+	}
+}
@@ -0,0 +1,167 @@
+// Package cfnprogress follows the CloudFormation events of the stacks in a
+// deploy and renders them as a compact table.
+package cfnprogress
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/basewarphq/bw/cmd/internal/cfnread"
+)
+
+const stackResourceType = "AWS::CloudFormation::Stack"
+
+const statusWaiting = "waiting"
+
+type Stack struct {
+	Name   string
+	Region string
+	// Baseline is the newest event from before the deploy started. It and
+	// everything older belong to earlier deploys and are ignored.
+	Baseline string
+	// TemplateResources is the resource count of the synthesized template.
+	// Updates only emit events for changed resources, so it only serves as
+	// the total while the stack is being created.
+	TemplateResources int
+
+	Status   string
+	Done     int
+	Total    int
+	Started  time.Time
+	Finished time.Time
+	// Failure is the first resource failure of this deploy, which is usually
+	// the cause of any later ones.
+	Failure *cfnread.StackEvent
+}
+
+// Update recomputes the progress from the stack's events, newest first as
+// returned by cfnread.StackEvents.
+func (s *Stack) Update(events []cfnread.StackEvent) {
+	var fresh []cfnread.StackEvent
+	for _, e := range events {
+		if e.ID == s.Baseline {
+			break
+		}
+		fresh = append(fresh, e)
+	}
+
+	s.Status, s.Done, s.Total, s.Failure = statusWaiting, 0, 0, nil
+	s.Started, s.Finished = time.Time{}, time.Time{}
+
+	latest := map[string]string{}
+	var stackFailure *cfnread.StackEvent
+	for i := len(fresh) - 1; i >= 0; i-- {
+		e := fresh[i]
+		if s.Started.IsZero() {
+			s.Started = e.Timestamp
+		}
+		if e.ResourceType == stackResourceType && e.LogicalID == s.Name {
+			s.Status = e.Status
+			if strings.HasSuffix(e.Status, "_IN_PROGRESS") {
+				s.Finished = time.Time{}
+			} else {
+				s.Finished = e.Timestamp
+			}
+			if stackFailure == nil && strings.HasSuffix(e.Status, "_FAILED") {
+				stackFailure = &e
+			}
+			continue
+		}
+		latest[e.LogicalID] = e.Status
+		if s.Failure == nil && strings.HasSuffix(e.Status, "_FAILED") &&
+			!strings.Contains(e.StatusReason, "cancelled") {
+			s.Failure = &e
+		}
+	}
+	if s.Failure == nil {
+		s.Failure = stackFailure
+	}
+
+	for _, status := range latest {
+		if strings.HasSuffix(status, "_COMPLETE") {
+			s.Done++
+		}
+	}
+	s.Total = len(latest)
+	if strings.HasPrefix(s.Status, "CREATE_") && s.TemplateResources > s.Total {
+		s.Total = s.TemplateResources
+	}
+}
+
+// Failed reports whether the stack's deploy failed or rolled back.
+func (s *Stack) Failed() bool {
+	return strings.Contains(s.Status, "FAILED") || strings.Contains(s.Status, "ROLLBACK")
+}
+
+func (s *Stack) Elapsed(now time.Time) time.Duration {
+	if s.Started.IsZero() {
+		return 0
+	}
+	end := now
+	if !s.Finished.IsZero() {
+		end = s.Finished
+	}
+	return end.Sub(s.Started).Round(time.Second)
+}
+
+// Render writes the progress table and returns the number of lines written.
+func Render(w io.Writer, stacks []*Stack, now time.Time) (int, error) {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "STACK\tREGION\tSTATUS\tRESOURCES\tELAPSED")
+	for _, s := range stacks {
+		resources, elapsed := "-", "-"
+		if s.Total > 0 {
+			resources = fmt.Sprintf("%d/%d", s.Done, s.Total)
+		}
+		if !s.Started.IsZero() {
+			elapsed = s.Elapsed(now).String()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", s.Name, s.Region, s.Status, resources, elapsed)
+	}
+	if err := tw.Flush(); err != nil {
+		return 0, err
+	}
+	lines := bytes.Count(buf.Bytes(), []byte("\n"))
+	_, err := w.Write(buf.Bytes())
+	return lines, err
+}
+
+// Live redraws the table in place on a terminal.
+type Live struct {
+	W     io.Writer
+	lines int
+}
+
+func (l *Live) Redraw(stacks []*Stack, now time.Time) error {
+	var buf bytes.Buffer
+	n, err := Render(&buf, stacks, now)
+	if err != nil {
+		return err
+	}
+	var out bytes.Buffer
+	if l.lines > 0 {
+		fmt.Fprintf(&out, "\x1b[%dA", l.lines)
+	}
+	for line := range strings.SplitSeq(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		out.WriteString("\x1b[2K" + line + "\n")
+	}
+	l.lines = n
+	_, err = l.W.Write(out.Bytes())
+	return err
+}
+
+// FailureLine describes the first failing resource event of a stack, or ""
+// when the stack has not failed.
+func FailureLine(s *Stack) string {
+	if s.Failure == nil {
+		return ""
+	}
+	e := s.Failure
+	return fmt.Sprintf("%s (%s): %s %s %s: %s",
+		s.Name, s.Region, e.LogicalID, e.ResourceType, e.Status, e.StatusReason)
+}
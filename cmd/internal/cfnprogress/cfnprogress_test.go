@@ -0,0 +1,109 @@
+package cfnprogress_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/basewarphq/bw/cmd/internal/cfnprogress"
+	"github.com/basewarphq/bw/cmd/internal/cfnread"
+)
+
+var t0 = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func event(id string, sec int, logicalID, typ, status, reason string) cfnread.StackEvent {
+	return cfnread.StackEvent{
+		ID: id, Timestamp: t0.Add(time.Duration(sec) * time.Second),
+		LogicalID: logicalID, ResourceType: typ, Status: status, StatusReason: reason,
+	}
+}
+
+// newestFirst reverses events written in chronological order.
+func newestFirst(events ...cfnread.StackEvent) []cfnread.StackEvent {
+	out := make([]cfnread.StackEvent, len(events))
+	for i, e := range events {
+		out[len(events)-1-i] = e
+	}
+	return out
+}
+
+func TestUpdate_IgnoresEventsBeforeBaseline(t *testing.T) {
+	t.Parallel()
+	s := &cfnprogress.Stack{Name: "bwappEucDev03", Baseline: "old-2"}
+	s.Update(newestFirst(
+		event("old-1", -100, "bwappEucDev03", "AWS::CloudFormation::Stack", "UPDATE_IN_PROGRESS", ""),
+		event("old-2", -90, "bwappEucDev03", "AWS::CloudFormation::Stack", "UPDATE_COMPLETE", ""),
+	))
+	if s.Status != "waiting" || s.Total != 0 {
+		t.Errorf("got status %q total %d, want waiting with no resources", s.Status, s.Total)
+	}
+}
+
+func TestUpdate_CreateUsesTemplateTotal(t *testing.T) {
+	t.Parallel()
+	s := &cfnprogress.Stack{Name: "bwappEucDev03", TemplateResources: 4}
+	s.Update(newestFirst(
+		event("1", 0, "bwappEucDev03", "AWS::CloudFormation::Stack", "CREATE_IN_PROGRESS", "User Initiated"),
+		event("2", 1, "Table", "AWS::DynamoDB::Table", "CREATE_IN_PROGRESS", ""),
+		event("3", 5, "Table", "AWS::DynamoDB::Table", "CREATE_COMPLETE", ""),
+		event("4", 6, "Role", "AWS::IAM::Role", "CREATE_IN_PROGRESS", ""),
+	))
+	if s.Status != "CREATE_IN_PROGRESS" {
+		t.Errorf("got status %q", s.Status)
+	}
+	if s.Done != 1 || s.Total != 4 {
+		t.Errorf("got %d/%d, want 1/4", s.Done, s.Total)
+	}
+	if got := s.Elapsed(t0.Add(30 * time.Second)); got != 30*time.Second {
+		t.Errorf("got elapsed %s, want 30s", got)
+	}
+}
+
+func TestUpdate_FirstFailure(t *testing.T) {
+	t.Parallel()
+	s := &cfnprogress.Stack{Name: "bwappEucDev03", Region: "eu-central-1"}
+	s.Update(newestFirst(
+		event("1", 0, "bwappEucDev03", "AWS::CloudFormation::Stack", "UPDATE_IN_PROGRESS", ""),
+		event("2", 1, "Fn", "AWS::Lambda::Function", "UPDATE_IN_PROGRESS", ""),
+		event("3", 2, "Zone", "AWS::Route53::HostedZone", "UPDATE_FAILED", "Invalid domain name"),
+		event("4", 3, "Fn", "AWS::Lambda::Function", "UPDATE_FAILED", "Resource update cancelled"),
+		event("5", 4, "bwappEucDev03", "AWS::CloudFormation::Stack", "UPDATE_ROLLBACK_IN_PROGRESS",
+			"The following resource(s) failed to update: [Zone]."),
+		event("6", 20, "bwappEucDev03", "AWS::CloudFormation::Stack", "UPDATE_ROLLBACK_COMPLETE", ""),
+	))
+	if !s.Failed() {
+		t.Errorf("status %q should count as failed", s.Status)
+	}
+	if s.Failure == nil || s.Failure.LogicalID != "Zone" {
+		t.Fatalf("got failure %+v, want Zone", s.Failure)
+	}
+	if line := cfnprogress.FailureLine(s); !strings.Contains(line, "Invalid domain name") {
+		t.Errorf("got %q", line)
+	}
+	if got := s.Elapsed(t0.Add(time.Hour)); got != 20*time.Second {
+		t.Errorf("finished stack should stop its clock, got %s", got)
+	}
+}
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+	stacks := []*cfnprogress.Stack{
+		{Name: "bwappEucShared", Region: "eu-central-1", Status: "waiting"},
+		{Name: "bwappEucDev03", Region: "eu-central-1", Status: "UPDATE_IN_PROGRESS", Done: 2, Total: 3, Started: t0},
+	}
+	var buf bytes.Buffer
+	n, err := cfnprogress.Render(&buf, stacks, t0.Add(5*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("got %d lines, want 3", n)
+	}
+	out := buf.String()
+	for _, want := range []string{"STACK", "bwappEucDev03", "2/3", "5s"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package cfnread
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/cockroachdb/errors"
+)
+
+// cache holds stack descriptions for the duration of one command run so
+// that lenses reading the same stacks describe them only once.
+type cache struct {
+	mu     sync.Mutex
+	stacks map[stackKey]cachedStack
+	// listed holds the regions whose stacks were all read by DescribeStacks,
+	// keyed by the name prefix of the listing.
+	listed map[clientKey][]string
+}
+
+type stackKey struct{ region, profile, name string }
+
+type cachedStack struct {
+	stack *Stack
+	err   error
+}
+
+type cacheKey struct{}
+
+// WithCache returns a context in which DescribeStack, StackOutputs and
+// DescribeStacks share one cache. Only use it for read-only runs such as
+// inspect: code that polls a stack during a deploy must see fresh state.
+func WithCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheKey{}, &cache{
+		stacks: map[stackKey]cachedStack{},
+		listed: map[clientKey][]string{},
+	})
+}
+
+func cacheFrom(ctx context.Context) *cache {
+	c, _ := ctx.Value(cacheKey{}).(*cache)
+	return c
+}
+
+func (c *cache) describeStack(ctx context.Context, region, profile, name string) (*Stack, error) {
+	key := stackKey{region: region, profile: profile, name: name}
+	c.mu.Lock()
+	if hit, ok := c.stacks[key]; ok {
+		c.mu.Unlock()
+		return hit.stack, hit.err
+	}
+	if c.coveredByListing(region, profile, name) {
+		c.mu.Unlock()
+		return nil, stackNotFound(name, region)
+	}
+	c.mu.Unlock()
+
+	stack, err := describeStack(ctx, region, profile, name)
+	if err == nil || errors.Is(err, ErrStackNotFound) {
+		c.mu.Lock()
+		c.stacks[key] = cachedStack{stack: stack, err: err}
+		c.mu.Unlock()
+	}
+	return stack, err
+}
+
+func (c *cache) describeStacks(ctx context.Context, region, profile, prefix string) ([]*Stack, error) {
+	c.mu.Lock()
+	if c.coveredByListing(region, profile, prefix) {
+		var stacks []*Stack
+		for key, hit := range c.stacks {
+			if key.region == region && key.profile == profile && hit.stack != nil &&
+				strings.HasPrefix(key.name, prefix) {
+				stacks = append(stacks, hit.stack)
+			}
+		}
+		c.mu.Unlock()
+		return stacks, nil
+	}
+	c.mu.Unlock()
+
+	stacks, err := describeStacks(ctx, region, profile, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, s := range stacks {
+		c.stacks[stackKey{region: region, profile: profile, name: s.Name}] = cachedStack{stack: s}
+	}
+	lk := clientKey{region: region, profile: profile}
+	c.listed[lk] = append(c.listed[lk], prefix)
+	return stacks, nil
+}
+
+// coveredByListing reports whether a DescribeStacks listing already covered
+// every stack named name. Callers hold c.mu.
+func (c *cache) coveredByListing(region, profile, name string) bool {
+	for _, prefix := range c.listed[clientKey{region: region, profile: profile}] {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,159 @@
+package cfnread
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go"
+	"github.com/cockroachdb/errors"
+)
+
+// fakeAPI serves DescribeStacks and DescribeChangeSet from memory; the
+// embedded interface is nil, so other calls panic.
+type fakeAPI struct {
+	stackAPI
+	stacks     []types.Stack
+	changeSets []cloudformation.DescribeChangeSetOutput
+	calls      atomic.Int32
+}
+
+func (f *fakeAPI) DescribeChangeSet(
+	_ context.Context, in *cloudformation.DescribeChangeSetInput, _ ...func(*cloudformation.Options),
+) (*cloudformation.DescribeChangeSetOutput, error) {
+	for _, cs := range f.changeSets {
+		if aws.ToString(cs.ChangeSetId) == *in.ChangeSetName {
+			return &cs, nil
+		}
+	}
+	return nil, &smithy.GenericAPIError{Code: "ChangeSetNotFound", Message: "ChangeSet [" + *in.ChangeSetName + "] does not exist"}
+}
+
+func (f *fakeAPI) DescribeStacks(
+	_ context.Context, in *cloudformation.DescribeStacksInput, _ ...func(*cloudformation.Options),
+) (*cloudformation.DescribeStacksOutput, error) {
+	f.calls.Add(1)
+	if in.StackName == nil {
+		// Two pages, to exercise the paginator.
+		if in.NextToken == nil {
+			return &cloudformation.DescribeStacksOutput{Stacks: f.stacks[:1], NextToken: aws.String("p2")}, nil
+		}
+		return &cloudformation.DescribeStacksOutput{Stacks: f.stacks[1:]}, nil
+	}
+	for _, s := range f.stacks {
+		if aws.ToString(s.StackName) == *in.StackName {
+			return &cloudformation.DescribeStacksOutput{Stacks: []types.Stack{s}}, nil
+		}
+	}
+	return nil, &smithy.GenericAPIError{Code: "ValidationError", Message: "Stack with id " + *in.StackName + " does not exist"}
+}
+
+func installFake(t *testing.T, region string, api *fakeAPI) {
+	t.Helper()
+	clientsMu.Lock()
+	clients[clientKey{region: region}] = api
+	clientsMu.Unlock()
+}
+
+func TestDescribeStack_Typed(t *testing.T) {
+	t.Parallel()
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	api := &fakeAPI{stacks: []types.Stack{{
+		StackName:    aws.String("bwappEuc1Prod"),
+		StackStatus:  types.StackStatusUpdateComplete,
+		CreationTime: &created,
+		Outputs:      []types.Output{{OutputKey: aws.String("GatewayURL"), OutputValue: aws.String("https://x")}},
+		Parameters: []types.Parameter{{
+			ParameterKey: aws.String("BootstrapVersion"), ParameterValue: aws.String("/cdk-bootstrap/bwapp/version"),
+			ResolvedValue: aws.String("30"),
+		}},
+		Tags: []types.Tag{{Key: aws.String("team"), Value: aws.String("platform")}},
+	}}}
+	installFake(t, "test-typed-1", api)
+
+	stack, err := DescribeStack(context.Background(), "test-typed-1", "", "bwappEuc1Prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stack.Status != "UPDATE_COMPLETE" || stack.LastUpdatedTime != "2026-01-02T03:04:05Z" {
+		t.Errorf("got %+v", stack)
+	}
+	if stack.Outputs["GatewayURL"] != "https://x" || stack.Parameters["BootstrapVersion"] != "30" ||
+		stack.Tags["team"] != "platform" {
+		t.Errorf("got %+v", stack)
+	}
+
+	_, err = DescribeStack(context.Background(), "test-typed-1", "", "missing")
+	if !errors.Is(err, ErrStackNotFound) {
+		t.Errorf("expected ErrStackNotFound, got %v", err)
+	}
+}
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+	api := &fakeAPI{stacks: []types.Stack{
+		{StackName: aws.String("bwappEuc1Shared")},
+		{StackName: aws.String("bwappEuc1Prod")},
+		{StackName: aws.String("otherStack")},
+	}}
+	installFake(t, "test-cache-1", api)
+	ctx := WithCache(context.Background())
+
+	stacks, err := DescribeStacks(ctx, "test-cache-1", "", "bwapp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stacks) != 2 || api.calls.Load() != 2 {
+		t.Fatalf("got %d stacks in %d calls, want 2 stacks in 2 pages", len(stacks), api.calls.Load())
+	}
+
+	if _, err := StackOutputs(ctx, "test-cache-1", "", "bwappEuc1Prod"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DescribeStack(ctx, "test-cache-1", "", "bwappEuc1Dev01"); !errors.Is(err, ErrStackNotFound) {
+		t.Errorf("stack missing from the listing should be not found, got %v", err)
+	}
+	if _, err := DescribeStacks(ctx, "test-cache-1", "", "bwappEuc1"); err != nil {
+		t.Fatal(err)
+	}
+	if n := api.calls.Load(); n != 2 {
+		t.Errorf("cached reads made %d more calls", n-2)
+	}
+
+	if _, err := DescribeStack(ctx, "test-cache-1", "", "otherStack"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DescribeStack(ctx, "test-cache-1", "", "otherStack"); err != nil {
+		t.Fatal(err)
+	}
+	if n := api.calls.Load(); n != 3 {
+		t.Errorf("expected one describe for a stack outside the listing, got %d calls", n-2)
+	}
+}
+
+func TestDescribeChangeSet(t *testing.T) {
+	t.Parallel()
+	api := &fakeAPI{changeSets: []cloudformation.DescribeChangeSetOutput{{
+		ChangeSetId:     aws.String("arn:cs"),
+		ChangeSetName:   aws.String("bw-abc"),
+		Status:          types.ChangeSetStatusFailed,
+		StatusReason:    aws.String("No updates are to be performed."),
+		ExecutionStatus: types.ExecutionStatusUnavailable,
+	}}}
+	installFake(t, "test-changeset-1", api)
+
+	cs, err := DescribeChangeSet(t.Context(), "test-changeset-1", "", "", "arn:cs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cs.Name != "bw-abc" || cs.Status != "FAILED" || cs.ExecutionStatus != "UNAVAILABLE" {
+		t.Errorf("got %+v", cs)
+	}
+	if _, err := DescribeChangeSet(t.Context(), "test-changeset-1", "", "", "arn:gone"); !errors.Is(err, ErrChangeSetNotFound) {
+		t.Errorf("got %v, want ErrChangeSetNotFound", err)
+	}
+}
@@ -0,0 +1,83 @@
+package cfnread
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/cockroachdb/errors"
+)
+
+var ErrChangeSetNotFound = errors.New("change set not found")
+
+type ChangeSet struct {
+	ID              string
+	Name            string
+	StackName       string
+	Status          string
+	StatusReason    string
+	ExecutionStatus string
+	CreationTime    time.Time
+}
+
+// DescribeChangeSet looks up a change set by name or ARN. stackName may be
+// empty when changeSet is an ARN.
+func DescribeChangeSet(ctx context.Context, region, profile, stackName, changeSet string) (*ChangeSet, error) {
+	api, err := client(ctx, region, profile)
+	if err != nil {
+		return nil, err
+	}
+	in := &cloudformation.DescribeChangeSetInput{ChangeSetName: aws.String(changeSet)}
+	if stackName != "" {
+		in.StackName = aws.String(stackName)
+	}
+	out, err := api.DescribeChangeSet(ctx, in)
+	if err != nil {
+		if notExist(err) {
+			return nil, errors.Mark(
+				errors.Newf("change set %s not found in %s", changeSet, region),
+				ErrChangeSetNotFound,
+			)
+		}
+		return nil, errors.Wrapf(err, "describing change set %s in %s", changeSet, region)
+	}
+	return &ChangeSet{
+		ID:              aws.ToString(out.ChangeSetId),
+		Name:            aws.ToString(out.ChangeSetName),
+		StackName:       aws.ToString(out.StackName),
+		Status:          string(out.Status),
+		StatusReason:    aws.ToString(out.StatusReason),
+		ExecutionStatus: string(out.ExecutionStatus),
+		CreationTime:    aws.ToTime(out.CreationTime),
+	}, nil
+}
+
+func ListChangeSets(ctx context.Context, region, profile, stackName string) ([]ChangeSet, error) {
+	api, err := client(ctx, region, profile)
+	if err != nil {
+		return nil, err
+	}
+	var sets []ChangeSet
+	pages := cloudformation.NewListChangeSetsPaginator(api, &cloudformation.ListChangeSetsInput{
+		StackName: aws.String(stackName),
+	})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing change sets of %s in %s", stackName, region)
+		}
+		for _, s := range page.Summaries {
+			sets = append(sets, ChangeSet{
+				ID:              aws.ToString(s.ChangeSetId),
+				Name:            aws.ToString(s.ChangeSetName),
+				StackName:       aws.ToString(s.StackName),
+				Status:          string(s.Status),
+				StatusReason:    aws.ToString(s.StatusReason),
+				ExecutionStatus: string(s.ExecutionStatus),
+				CreationTime:    aws.ToTime(s.CreationTime),
+			})
+		}
+	}
+	return sets, nil
+}
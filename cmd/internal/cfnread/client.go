@@ -0,0 +1,68 @@
+package cfnread
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/smithy-go"
+	"github.com/cockroachdb/errors"
+)
+
+// stackAPI is the part of the CloudFormation API the reader uses.
+type stackAPI interface {
+	cloudformation.DescribeStacksAPIClient
+	cloudformation.DescribeChangeSetAPIClient
+	cloudformation.ListChangeSetsAPIClient
+	cloudformation.DescribeStackEventsAPIClient
+	cloudformation.DescribeStackResourceDriftsAPIClient
+	DetectStackDrift(
+		ctx context.Context, in *cloudformation.DetectStackDriftInput, optFns ...func(*cloudformation.Options),
+	) (*cloudformation.DetectStackDriftOutput, error)
+	DescribeStackDriftDetectionStatus(
+		ctx context.Context, in *cloudformation.DescribeStackDriftDetectionStatusInput,
+		optFns ...func(*cloudformation.Options),
+	) (*cloudformation.DescribeStackDriftDetectionStatusOutput, error)
+}
+
+type clientKey struct{ region, profile string }
+
+var (
+	clientsMu sync.Mutex
+	clients   = map[clientKey]stackAPI{}
+)
+
+// client returns the CloudFormation client for a region and profile,
+// loading the shared AWS config the first time. An empty profile uses the
+// default credential chain, like the aws CLI without --profile.
+func client(ctx context.Context, region, profile string) (stackAPI, error) {
+	key := clientKey{region: region, profile: profile}
+
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+	if c, ok := clients[key]; ok {
+		return c, nil
+	}
+
+	opts := []func(*config.LoadOptions) error{config.WithRegion(region)}
+	if profile != "" {
+		opts = append(opts, config.WithSharedConfigProfile(profile))
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading AWS config for profile %q", profile)
+	}
+	c := cloudformation.NewFromConfig(cfg)
+	clients[key] = c
+	return c, nil
+}
+
+// notExist reports whether err is CloudFormation saying the stack or change
+// set it was asked about does not exist.
+func notExist(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) &&
+		(apiErr.ErrorCode() == "ChangeSetNotFound" || strings.Contains(apiErr.ErrorMessage(), "does not exist"))
+}
@@ -0,0 +1,110 @@
+package cfnread
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/cockroachdb/errors"
+)
+
+const driftPollInterval = 3 * time.Second
+
+// DetectStackDrift starts drift detection for a stack and waits for it to
+// finish. It returns the stack drift status: IN_SYNC, DRIFTED or
+// NOT_CHECKED.
+func DetectStackDrift(ctx context.Context, region, profile, stackName string) (string, error) {
+	api, err := client(ctx, region, profile)
+	if err != nil {
+		return "", err
+	}
+	started, err := api.DetectStackDrift(ctx, &cloudformation.DetectStackDriftInput{
+		StackName: aws.String(stackName),
+	})
+	if err != nil {
+		if notExist(err) {
+			return "", stackNotFound(stackName, region)
+		}
+		return "", errors.Wrapf(err, "starting drift detection for %s in %s", stackName, region)
+	}
+
+	for {
+		status, err := api.DescribeStackDriftDetectionStatus(ctx, &cloudformation.DescribeStackDriftDetectionStatusInput{
+			StackDriftDetectionId: started.StackDriftDetectionId,
+		})
+		if err != nil {
+			return "", errors.Wrapf(err, "drift detection for %s", stackName)
+		}
+		switch status.DetectionStatus {
+		case types.StackDriftDetectionStatusDetectionComplete:
+			return string(status.StackDriftStatus), nil
+		case types.StackDriftDetectionStatusDetectionFailed:
+			return "", errors.Newf("drift detection for %s failed: %s",
+				stackName, aws.ToString(status.DetectionStatusReason))
+		case types.StackDriftDetectionStatusDetectionInProgress:
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(driftPollInterval):
+		}
+	}
+}
+
+type ResourceDrift struct {
+	LogicalID    string
+	ResourceType string
+	// Status is MODIFIED or DELETED.
+	Status      string
+	Differences []PropertyDifference
+}
+
+type PropertyDifference struct {
+	Path     string
+	Type     string
+	Expected string
+	Actual   string
+}
+
+// StackResourceDrifts returns the resources of a stack that were modified or
+// deleted outside CloudFormation, as found by the last drift detection.
+func StackResourceDrifts(ctx context.Context, region, profile, stackName string) ([]ResourceDrift, error) {
+	api, err := client(ctx, region, profile)
+	if err != nil {
+		return nil, err
+	}
+	var drifts []ResourceDrift
+	pages := cloudformation.NewDescribeStackResourceDriftsPaginator(api, &cloudformation.DescribeStackResourceDriftsInput{
+		StackName: aws.String(stackName),
+		StackResourceDriftStatusFilters: []types.StackResourceDriftStatus{
+			types.StackResourceDriftStatusModified,
+			types.StackResourceDriftStatusDeleted,
+		},
+	})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "describing resource drifts of %s in %s", stackName, region)
+		}
+		for _, d := range page.StackResourceDrifts {
+			drift := ResourceDrift{
+				LogicalID:    aws.ToString(d.LogicalResourceId),
+				ResourceType: aws.ToString(d.ResourceType),
+				Status:       string(d.StackResourceDriftStatus),
+			}
+			for _, p := range d.PropertyDifferences {
+				drift.Differences = append(drift.Differences, PropertyDifference{
+					Path:     aws.ToString(p.PropertyPath),
+					Type:     string(p.DifferenceType),
+					Expected: aws.ToString(p.ExpectedValue),
+					Actual:   aws.ToString(p.ActualValue),
+				})
+			}
+			drifts = append(drifts, drift)
+		}
+	}
+	return drifts, nil
+}
@@ -0,0 +1,51 @@
+package cfnread
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/cockroachdb/errors"
+)
+
+type StackEvent struct {
+	ID           string
+	Timestamp    time.Time
+	LogicalID    string
+	ResourceType string
+	Status       string
+	StatusReason string
+}
+
+// StackEvents returns the most recent events of a stack, newest first. A
+// stack that does not exist yet has no events and is not an error.
+func StackEvents(ctx context.Context, region, profile, stackName string) ([]StackEvent, error) {
+	api, err := client(ctx, region, profile)
+	if err != nil {
+		return nil, err
+	}
+	// The first page holds the latest events, which is all progress needs.
+	out, err := api.DescribeStackEvents(ctx, &cloudformation.DescribeStackEventsInput{
+		StackName: aws.String(stackName),
+	})
+	if err != nil {
+		if notExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "describing events of %s in %s", stackName, region)
+	}
+
+	events := make([]StackEvent, 0, len(out.StackEvents))
+	for _, e := range out.StackEvents {
+		events = append(events, StackEvent{
+			ID:           aws.ToString(e.EventId),
+			Timestamp:    aws.ToTime(e.Timestamp),
+			LogicalID:    aws.ToString(e.LogicalResourceId),
+			ResourceType: aws.ToString(e.ResourceType),
+			Status:       string(e.ResourceStatus),
+			StatusReason: aws.ToString(e.ResourceStatusReason),
+		})
+	}
+	return events, nil
+}
@@ -2,49 +2,142 @@ package cfnread
 
 import (
 	"context"
-	"encoding/json"
+	"strings"
+	"time"
 
-	"github.com/basewarphq/bw/cmd/internal/cmdexec"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
 	"github.com/cockroachdb/errors"
 )
 
-type describeStacksResponse struct {
-	Stacks []struct {
-		Outputs []struct {
-			OutputKey   string `json:"OutputKey"`
-			OutputValue string `json:"OutputValue"`
-		} `json:"Outputs"`
-	} `json:"Stacks"`
+var ErrStackNotFound = errors.New("stack not found")
+
+type Stack struct {
+	Name   string
+	Region string
+	Status string
+	// LastUpdatedTime is RFC 3339, and the creation time for stacks that
+	// were never updated.
+	LastUpdatedTime string
+	ChangeSetID     string
+	Outputs         map[string]string
+	Parameters      map[string]string
+	Tags            map[string]string
 }
 
-func StackOutputs(ctx context.Context, region, profile, stackName string) (map[string]string, error) {
-	args := []string{
-		"cloudformation", "describe-stacks",
-		"--no-cli-pager",
-		"--region", region,
-		"--stack-name", stackName,
-		"--output", "json",
+// DescribeStack reads one stack. Inside a context from WithCache the result
+// is cached, including ErrStackNotFound.
+func DescribeStack(ctx context.Context, region, profile, stackName string) (*Stack, error) {
+	if c := cacheFrom(ctx); c != nil {
+		return c.describeStack(ctx, region, profile, stackName)
 	}
-	if profile != "" {
-		args = append(args, "--profile", profile)
+	return describeStack(ctx, region, profile, stackName)
+}
+
+func describeStack(ctx context.Context, region, profile, stackName string) (*Stack, error) {
+	api, err := client(ctx, region, profile)
+	if err != nil {
+		return nil, err
 	}
-	out, err := cmdexec.Output(ctx, "/", "aws", args...)
+	out, err := api.DescribeStacks(ctx, &cloudformation.DescribeStacksInput{StackName: aws.String(stackName)})
 	if err != nil {
+		if notExist(err) {
+			return nil, stackNotFound(stackName, region)
+		}
 		return nil, errors.Wrapf(err, "describing stack %s in %s", stackName, region)
 	}
+	if len(out.Stacks) == 0 {
+		return nil, stackNotFound(stackName, region)
+	}
+	return stackFromSDK(out.Stacks[0], region), nil
+}
+
+// DescribeStacks lists every stack in the region whose name starts with
+// prefix, paging through DescribeStacks once. Inside a context from
+// WithCache, the listing also answers later DescribeStack calls for the
+// region.
+func DescribeStacks(ctx context.Context, region, profile, prefix string) ([]*Stack, error) {
+	if c := cacheFrom(ctx); c != nil {
+		return c.describeStacks(ctx, region, profile, prefix)
+	}
+	return describeStacks(ctx, region, profile, prefix)
+}
+
+func describeStacks(ctx context.Context, region, profile, prefix string) ([]*Stack, error) {
+	api, err := client(ctx, region, profile)
+	if err != nil {
+		return nil, err
+	}
+	var stacks []*Stack
+	pages := cloudformation.NewDescribeStacksPaginator(api, &cloudformation.DescribeStacksInput{})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing stacks in %s", region)
+		}
+		for _, s := range page.Stacks {
+			if strings.HasPrefix(aws.ToString(s.StackName), prefix) {
+				stacks = append(stacks, stackFromSDK(s, region))
+			}
+		}
+	}
+	return stacks, nil
+}
 
-	var resp describeStacksResponse
-	if err := json.Unmarshal([]byte(out), &resp); err != nil {
-		return nil, errors.Wrapf(err, "parsing stack outputs for %s", stackName)
+func StackOutputs(ctx context.Context, region, profile, stackName string) (map[string]string, error) {
+	stack, err := DescribeStack(ctx, region, profile, stackName)
+	if err != nil {
+		return nil, err
 	}
+	return stack.Outputs, nil
+}
+
+func stackNotFound(stackName, region string) error {
+	return errors.Mark(errors.Newf("stack %s not found in %s", stackName, region), ErrStackNotFound)
+}
 
-	if len(resp.Stacks) == 0 {
-		return nil, errors.Newf("stack %s not found in %s", stackName, region)
+func stackFromSDK(s types.Stack, region string) *Stack {
+	stack := &Stack{
+		Name:        aws.ToString(s.StackName),
+		Region:      region,
+		Status:      string(s.StackStatus),
+		ChangeSetID: aws.ToString(s.ChangeSetId),
+		Outputs:     make(map[string]string, len(s.Outputs)),
+		Parameters:  make(map[string]string, len(s.Parameters)),
+		Tags:        make(map[string]string, len(s.Tags)),
 	}
+	updated := s.LastUpdatedTime
+	if updated == nil {
+		updated = s.CreationTime
+	}
+	if updated != nil {
+		stack.LastUpdatedTime = updated.UTC().Format(time.RFC3339)
+	}
+	for _, o := range s.Outputs {
+		stack.Outputs[aws.ToString(o.OutputKey)] = aws.ToString(o.OutputValue)
+	}
+	for _, p := range s.Parameters {
+		value := aws.ToString(p.ParameterValue)
+		if p.ResolvedValue != nil {
+			value = aws.ToString(p.ResolvedValue)
+		}
+		stack.Parameters[aws.ToString(p.ParameterKey)] = value
+	}
+	for _, t := range s.Tags {
+		stack.Tags[aws.ToString(t.Key)] = aws.ToString(t.Value)
+	}
+	return stack
+}
 
-	outputs := make(map[string]string, len(resp.Stacks[0].Outputs))
-	for _, o := range resp.Stacks[0].Outputs {
-		outputs[o.OutputKey] = o.OutputValue
+// ChangeSetName returns the name of the change set a stack was last updated
+// with, taken from its change set ARN
+// (arn:aws:cloudformation:{region}:{account}:changeSet/{name}/{id}).
+func (s *Stack) ChangeSetName() string {
+	_, rest, ok := strings.Cut(s.ChangeSetID, ":changeSet/")
+	if !ok {
+		return ""
 	}
-	return outputs, nil
+	name, _, _ := strings.Cut(rest, "/")
+	return name
 }
@@ -1,37 +1,126 @@
 package cfnvalidate
 
 import (
+	"fmt"
 	"os"
+	"sort"
+	"strings"
 
+	"github.com/basewarphq/bw/cmd/internal/cdkmanifest"
 	"github.com/cockroachdb/errors"
 	"gopkg.in/yaml.v3"
 )
 
-func PreBootstrapTemplate(templatePath string) error {
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Finding is one problem in a template, located by file line and column.
+type Finding struct {
+	File     string
+	Line     int
+	Column   int
+	Severity Severity
+	Message  string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s", f.File, f.Line, f.Column, f.Severity, f.Message)
+}
+
+type Options struct {
+	// Parameters are the values that will be passed to the stack. When
+	// non-nil they are checked against the declared Parameters.
+	Parameters map[string]string
+	// PreBootstrap checks the outputs the pre-bootstrap flow reads.
+	PreBootstrap bool
+}
+
+// PreBootstrapTemplate lints a pre-bootstrap template against the parameters
+// it will be deployed with and fails on any error finding.
+func PreBootstrapTemplate(templatePath string, params map[string]string) error {
+	findings, err := Lint(templatePath, Options{Parameters: params, PreBootstrap: true})
+	if err != nil {
+		return err
+	}
+	return Errors(findings)
+}
+
+// Lint parses a YAML or JSON CloudFormation template and returns its
+// findings ordered by position. The error is only for templates that cannot
+// be read or parsed at all.
+func Lint(templatePath string, opts Options) ([]Finding, error) {
 	data, err := os.ReadFile(templatePath)
 	if err != nil {
-		return errors.Wrapf(err, "reading template %s", templatePath)
+		return nil, errors.Wrapf(err, "reading template %s", templatePath)
 	}
 
 	var doc yaml.Node
 	if err := yaml.Unmarshal(data, &doc); err != nil {
-		return errors.Wrap(err, "parsing template YAML")
+		return nil, errors.Wrap(err, "parsing template YAML")
 	}
 
 	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
-		return errors.New("invalid YAML document")
+		return nil, errors.New("invalid YAML document")
 	}
 
 	root := doc.Content[0]
 	if root.Kind != yaml.MappingNode {
-		return errors.New("template root is not a mapping")
+		return nil, errors.New("template root is not a mapping")
 	}
 
-	if findMappingValue(root, "Resources") == nil {
-		return errors.New("template has no Resources section")
+	l := newLinter(templatePath, root)
+	l.lint(opts)
+	sort.SliceStable(l.findings, func(i, j int) bool {
+		a, b := l.findings[i], l.findings[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return l.findings, nil
+}
+
+// CloudAssembly lints the stack templates of the cloud assembly in a cdk.out
+// directory, including those of nested stacks. Templates are taken from the
+// assembly manifest, so files left behind by earlier synths are ignored.
+func CloudAssembly(cdkOutDir string) ([]Finding, error) {
+	asm, err := cdkmanifest.Load(cdkOutDir)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, s := range asm.Stacks {
+		paths = append(paths, s.TemplateFile)
+		paths = append(paths, s.NestedTemplates...)
+	}
+	var findings []Finding
+	for _, p := range paths {
+		fs, err := Lint(p, Options{})
+		if err != nil {
+			return nil, errors.Wrapf(err, "linting %s", p)
+		}
+		findings = append(findings, fs...)
 	}
+	return findings, nil
+}
 
-	return nil
+// Errors returns an error listing the error findings, or nil when there are
+// only warnings.
+func Errors(findings []Finding) error {
+	var lines []string
+	for _, f := range findings {
+		if f.Severity == SeverityError {
+			lines = append(lines, f.String())
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return errors.Newf("template has %d error(s):\n  %s", len(lines), strings.Join(lines, "\n  "))
 }
 
 func findMappingValue(node *yaml.Node, key string) *yaml.Node {
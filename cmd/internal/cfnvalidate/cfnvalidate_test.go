@@ -24,7 +24,7 @@ Outputs:
   PermissionBoundaryName:
     Value: my-boundary
 `)
-	if err := cfnvalidate.PreBootstrapTemplate(path); err != nil {
+	if err := cfnvalidate.PreBootstrapTemplate(path, nil); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
@@ -36,7 +36,7 @@ Resources:
   MyPolicy:
     Type: AWS::IAM::ManagedPolicy
 `)
-	if err := cfnvalidate.PreBootstrapTemplate(path); err != nil {
+	if err := cfnvalidate.PreBootstrapTemplate(path, nil); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
@@ -48,7 +48,7 @@ Outputs:
   Foo:
     Value: bar
 `)
-	err := cfnvalidate.PreBootstrapTemplate(path)
+	err := cfnvalidate.PreBootstrapTemplate(path, nil)
 	if err == nil {
 		t.Fatal("expected error")
 	}
@@ -60,7 +60,7 @@ Outputs:
 func TestPreBootstrapTemplate_InvalidYAML(t *testing.T) {
 	t.Parallel()
 	path := writeTemp(t, `{{{invalid`)
-	err := cfnvalidate.PreBootstrapTemplate(path)
+	err := cfnvalidate.PreBootstrapTemplate(path, nil)
 	if err == nil {
 		t.Fatal("expected error")
 	}
@@ -68,7 +68,7 @@ func TestPreBootstrapTemplate_InvalidYAML(t *testing.T) {
 
 func TestPreBootstrapTemplate_FileNotFound(t *testing.T) {
 	t.Parallel()
-	err := cfnvalidate.PreBootstrapTemplate("/nonexistent/template.yaml")
+	err := cfnvalidate.PreBootstrapTemplate("/nonexistent/template.yaml", nil)
 	if err == nil {
 		t.Fatal("expected error")
 	}
@@ -83,3 +83,173 @@ func writeTemp(t *testing.T, content string) string {
 	}
 	return path
 }
+
+func lint(t *testing.T, content string, opts cfnvalidate.Options) []cfnvalidate.Finding {
+	t.Helper()
+	findings, err := cfnvalidate.Lint(writeTemp(t, content), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return findings
+}
+
+func assertFindings(t *testing.T, findings []cfnvalidate.Finding, want ...string) {
+	t.Helper()
+	if len(findings) != len(want) {
+		t.Fatalf("got %d findings, want %d: %v", len(findings), len(want), findings)
+	}
+	for i, w := range want {
+		if got := findings[i].String(); !strings.Contains(got, w) {
+			t.Errorf("finding %d: got %q, want it to contain %q", i, got, w)
+		}
+	}
+}
+
+func TestLint_References(t *testing.T) {
+	t.Parallel()
+	findings := lint(t, `AWSTemplateFormatVersion: "2010-09-09"
+Parameters:
+  Repo:
+    Type: String
+Conditions:
+  IsProd: !Equals [!Ref AWS::AccountId, "111111111111"]
+Resources:
+  Role:
+    Type: AWS::IAM::Role
+    Condition: IsStaging
+    Properties:
+      RoleName: !Sub "${Repo}-${Missing}-${AWS::Region}-${!Literal}"
+      Description: !Sub
+        - "${Name} in ${Policy.Arn}"
+        - Name: !Ref Repo
+      ManagedPolicyArns:
+        - !Ref Policy
+        - !GetAtt Nope.Arn
+  Policy:
+    Type: AWS::IAM::ManagedPolicy
+    DependsOn: [Role, Ghost]
+    Properties:
+      PolicyDocument:
+        Statement: []
+`, cfnvalidate.Options{})
+	assertFindings(t, findings,
+		":10:16: error: condition \"IsStaging\" is not declared",
+		":12:17: error: Sub variable target \"Missing\" is not a parameter or resource",
+		":18:11: error: GetAtt target \"Nope\" is not a resource",
+		":21:23: error: DependsOn target \"Ghost\" is not a resource",
+	)
+}
+
+func TestLint_Parameters(t *testing.T) {
+	t.Parallel()
+	template := `Parameters:
+  Repo:
+    Type: String
+  Branch:
+    Type: String
+    Default: main
+  Unused:
+    Type: String
+    Default: x
+Resources:
+  Topic:
+    Type: AWS::SNS::Topic
+    Properties:
+      TopicName: !Sub "${Repo}-${Branch}"
+`
+	assertFindings(t, lint(t, template, cfnvalidate.Options{}),
+		":7:3: warning: parameter \"Unused\" is never used",
+	)
+
+	findings := lint(t, template, cfnvalidate.Options{Parameters: map[string]string{"Extra": "1"}})
+	assertFindings(t, findings,
+		":1:1: error: supplied parameter \"Extra\" is not declared",
+		":2:3: error: parameter \"Repo\" has no Default and no value is supplied",
+		":7:3: warning: parameter \"Unused\" is never used",
+	)
+	if err := cfnvalidate.Errors(findings); err == nil || !strings.Contains(err.Error(), "2 error(s)") {
+		t.Errorf("expected 2 errors, got %v", err)
+	}
+}
+
+func TestLint_PreBootstrapOutputs(t *testing.T) {
+	t.Parallel()
+	findings := lint(t, `Resources:
+  Policy:
+    Type: AWS::IAM::ManagedPolicy
+    Properties:
+      ManagedPolicyName: boundary
+      PolicyDocument:
+        Statement: []
+  Bucket:
+    Type: AWS::S3::Bucket
+Outputs:
+  ExecutionPolicyArn:
+    Value: !Ref Bucket
+  PermissionBoundaryName:
+    Value: !Ref Policy
+`, cfnvalidate.Options{PreBootstrap: true})
+	assertFindings(t, findings,
+		":12:12: error: ExecutionPolicyArn refers to a AWS::S3::Bucket, not a managed policy",
+		":14:12: error: PermissionBoundaryName: Ref of a managed policy returns its ARN",
+	)
+
+	findings = lint(t, `Resources:
+  Policy:
+    Type: AWS::IAM::ManagedPolicy
+Outputs:
+  ExecutionPolicyArn:
+    Value: arn:aws:iam::aws:policy/AdministratorAccess,my-policy
+  PermissionBoundaryName:
+    Value: !Sub "arn:aws:iam::${AWS::AccountId}:policy/boundary"
+`, cfnvalidate.Options{PreBootstrap: true})
+	assertFindings(t, findings,
+		":6:12: error: ExecutionPolicyArn must be a policy ARN, got \"my-policy\"",
+		":8:12: error: PermissionBoundaryName must be a policy name, not an ARN",
+	)
+}
+
+func TestCloudAssembly(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	template := `{
+  "Parameters": {
+    "BootstrapVersion": {"Type": "AWS::SSM::Parameter::Value<String>", "Default": "/cdk-bootstrap/bwapp/version"}
+  },
+  "Resources": {
+    "Fn": {"Type": "AWS::Lambda::Function", "Properties": {
+      "Role": {"Fn::GetAtt": ["FnRole", "Arn"]},
+      "Code": {"S3Bucket": {"Fn::Sub": "cdk-bwapp-assets-${AWS::AccountId}-${AWS::Region}"}}
+    }},
+    "FnRole": {"Type": "AWS::IAM::Role"},
+    "Alias": {"Type": "AWS::Lambda::Alias", "Properties": {"FunctionName": {"Ref": "Fnn"}}},
+    "Nested": {"Type": "AWS::CloudFormation::Stack", "Metadata": {"aws:asset:path": "Nested.nested.template.json"}}
+  },
+  "Rules": {
+    "CheckBootstrapVersion": {"Assertions": [{"Assert": {"Fn::Not": [{"Fn::Contains": [["1"], {"Ref": "BootstrapVersion"}]}]}}]}
+  }
+}`
+	broken := `{"Resources": {"Alias": {"Type": "AWS::Lambda::Alias", "Properties": {"FunctionName": {"Ref": "Gone"}}}}}`
+	files := map[string]string{
+		"manifest.json": `{"artifacts": {"bwappEuc1Prod": {
+  "type": "aws:cloudformation:stack",
+  "properties": {"templateFile": "bwappEuc1Prod.template.json"}
+}}}`,
+		"bwappEuc1Prod.template.json":  template,
+		"Nested.nested.template.json":  broken,
+		"bwappEuc1Stale.template.json": broken,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	findings, err := cfnvalidate.CloudAssembly(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertFindings(t, findings,
+		"bwappEuc1Prod.template.json:11:84: error: Ref target \"Fnn\" is not a parameter or resource",
+		"Nested.nested.template.json:1:95: error: Ref target \"Gone\" is not a parameter or resource",
+	)
+}
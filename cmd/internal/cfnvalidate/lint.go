@@ -0,0 +1,393 @@
+package cfnvalidate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Outputs the pre-bootstrap flow passes on to cdk bootstrap.
+const (
+	outputExecutionPolicyArn     = "ExecutionPolicyArn"
+	outputPermissionBoundaryName = "PermissionBoundaryName"
+)
+
+var subVarRe = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+type linter struct {
+	file       string
+	root       *yaml.Node
+	parameters map[string]*yaml.Node
+	resources  map[string]*yaml.Node
+	conditions map[string]*yaml.Node
+	usedParams map[string]bool
+	findings   []Finding
+}
+
+func newLinter(file string, root *yaml.Node) *linter {
+	return &linter{
+		file:       file,
+		root:       root,
+		parameters: sectionEntries(findMappingValue(root, "Parameters")),
+		resources:  sectionEntries(findMappingValue(root, "Resources")),
+		conditions: sectionEntries(findMappingValue(root, "Conditions")),
+		usedParams: map[string]bool{},
+	}
+}
+
+// sectionEntries maps the entries of a top-level section to their key nodes,
+// which carry the position to report.
+func sectionEntries(section *yaml.Node) map[string]*yaml.Node {
+	entries := map[string]*yaml.Node{}
+	if section == nil || section.Kind != yaml.MappingNode {
+		return entries
+	}
+	for i := 0; i < len(section.Content)-1; i += 2 {
+		entries[section.Content[i].Value] = section.Content[i]
+	}
+	return entries
+}
+
+func (l *linter) add(node *yaml.Node, sev Severity, format string, args ...any) {
+	l.findings = append(l.findings, Finding{
+		File: l.file, Line: node.Line, Column: node.Column, Severity: sev, Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (l *linter) lint(opts Options) {
+	resources := findMappingValue(l.root, "Resources")
+	switch {
+	case resources == nil:
+		l.add(l.root, SeverityError, "template has no Resources section")
+	case resources.Kind != yaml.MappingNode || len(resources.Content) == 0:
+		l.add(resources, SeverityError, "Resources must be a non-empty mapping")
+	default:
+		l.lintResources(resources)
+	}
+
+	for _, section := range []string{"Conditions", "Outputs", "Rules"} {
+		if node := findMappingValue(l.root, section); node != nil {
+			l.walk(node)
+		}
+	}
+
+	for name, key := range l.parameters {
+		if !l.usedParams[name] {
+			l.add(key, SeverityWarning, "parameter %q is never used", name)
+		}
+	}
+
+	if opts.Parameters != nil {
+		l.lintSuppliedParameters(opts.Parameters)
+	}
+	if opts.PreBootstrap {
+		l.lintPreBootstrapOutputs()
+	}
+}
+
+func (l *linter) lintResources(resources *yaml.Node) {
+	for i := 0; i < len(resources.Content)-1; i += 2 {
+		key, resource := resources.Content[i], resources.Content[i+1]
+		if resource.Kind != yaml.MappingNode {
+			l.add(key, SeverityError, "resource %q must be a mapping", key.Value)
+			continue
+		}
+		typ := findMappingValue(resource, "Type")
+		if typ == nil || typ.Kind != yaml.ScalarNode || typ.Value == "" {
+			l.add(key, SeverityError, "resource %q has no Type", key.Value)
+		}
+		if cond := findMappingValue(resource, "Condition"); cond != nil {
+			l.checkCondition(cond)
+		}
+		if dep := findMappingValue(resource, "DependsOn"); dep != nil {
+			for _, n := range scalars(dep) {
+				if _, ok := l.resources[n.Value]; !ok {
+					l.add(n, SeverityError, "DependsOn target %q is not a resource", n.Value)
+				}
+			}
+		}
+		l.walk(resource)
+	}
+}
+
+// walk visits every intrinsic function below node, in both the short tag
+// form (!Ref) and the long mapping form (Ref:, Fn::GetAtt:).
+func (l *linter) walk(node *yaml.Node) {
+	switch node.Tag {
+	case "!Ref":
+		l.checkRef(node)
+		return
+	case "!GetAtt":
+		l.checkGetAtt(node)
+		return
+	case "!Sub":
+		l.checkSub(node)
+		return
+	case "!If":
+		if node.Kind == yaml.SequenceNode && len(node.Content) > 0 {
+			l.checkCondition(node.Content[0])
+		}
+	case "!Condition":
+		l.checkCondition(node)
+		return
+	}
+
+	if node.Kind == yaml.MappingNode && len(node.Content) == 2 {
+		fn, arg := node.Content[0].Value, node.Content[1]
+		switch fn {
+		case "Ref":
+			l.checkRef(arg)
+			return
+		case "Fn::GetAtt":
+			l.checkGetAtt(arg)
+			return
+		case "Fn::Sub":
+			l.checkSub(arg)
+			return
+		case "Fn::If":
+			if arg.Kind == yaml.SequenceNode && len(arg.Content) > 0 {
+				l.checkCondition(arg.Content[0])
+			}
+		case "Condition":
+			if arg.Kind == yaml.ScalarNode {
+				l.checkCondition(arg)
+				return
+			}
+		}
+	}
+
+	for _, child := range node.Content {
+		l.walk(child)
+	}
+}
+
+func (l *linter) checkRef(arg *yaml.Node) {
+	if arg.Kind != yaml.ScalarNode {
+		l.add(arg, SeverityError, "Ref takes the name of a parameter or resource")
+		return
+	}
+	l.checkName(arg, arg.Value, "Ref")
+}
+
+// checkName resolves a Ref-style name against pseudo parameters, parameters
+// and resources.
+func (l *linter) checkName(node *yaml.Node, name, fn string) {
+	switch {
+	case strings.HasPrefix(name, "AWS::"):
+	case l.parameters[name] != nil:
+		l.usedParams[name] = true
+	case l.resources[name] != nil:
+	default:
+		l.add(node, SeverityError, "%s target %q is not a parameter or resource", fn, name)
+	}
+}
+
+func (l *linter) checkGetAtt(arg *yaml.Node) {
+	var target *yaml.Node
+	var name string
+	switch {
+	case arg.Kind == yaml.ScalarNode:
+		target = arg
+		name, _, _ = strings.Cut(arg.Value, ".")
+	case arg.Kind == yaml.SequenceNode && len(arg.Content) == 2 && arg.Content[0].Kind == yaml.ScalarNode:
+		target = arg.Content[0]
+		name = target.Value
+		l.walk(arg.Content[1])
+	default:
+		l.add(arg, SeverityError, "GetAtt takes Resource.Attribute or [Resource, Attribute]")
+		return
+	}
+	if l.resources[name] == nil {
+		l.add(target, SeverityError, "GetAtt target %q is not a resource", name)
+	}
+}
+
+func (l *linter) checkSub(arg *yaml.Node) {
+	str := arg
+	locals := map[string]bool{}
+	if arg.Kind == yaml.SequenceNode {
+		if len(arg.Content) != 2 || arg.Content[0].Kind != yaml.ScalarNode || arg.Content[1].Kind != yaml.MappingNode {
+			l.add(arg, SeverityError, "Sub takes a string or [string, {variables}]")
+			return
+		}
+		str = arg.Content[0]
+		vars := arg.Content[1]
+		for i := 0; i < len(vars.Content)-1; i += 2 {
+			locals[vars.Content[i].Value] = true
+			l.walk(vars.Content[i+1])
+		}
+	}
+	if str.Kind != yaml.ScalarNode {
+		l.add(str, SeverityError, "Sub takes a string or [string, {variables}]")
+		return
+	}
+
+	for _, m := range subVarRe.FindAllStringSubmatch(str.Value, -1) {
+		v := strings.TrimSpace(m[1])
+		if strings.HasPrefix(v, "!") || locals[v] {
+			continue
+		}
+		if name, _, isAttr := strings.Cut(v, "."); isAttr && !strings.HasPrefix(v, "AWS::") {
+			if l.resources[name] == nil {
+				l.add(str, SeverityError, "Sub variable ${%s} is not a resource attribute", v)
+			}
+			continue
+		}
+		l.checkName(str, v, "Sub variable")
+	}
+}
+
+func (l *linter) checkCondition(node *yaml.Node) {
+	if node.Kind != yaml.ScalarNode {
+		return
+	}
+	if l.conditions[node.Value] == nil {
+		l.add(node, SeverityError, "condition %q is not declared", node.Value)
+	}
+}
+
+func (l *linter) lintSuppliedParameters(supplied map[string]string) {
+	for name, key := range l.parameters {
+		if _, ok := supplied[name]; ok {
+			continue
+		}
+		param := findMappingValue(l.root, "Parameters")
+		if findMappingValue(findMappingValue(param, name), "Default") == nil {
+			l.add(key, SeverityError, "parameter %q has no Default and no value is supplied", name)
+		}
+	}
+	for name := range supplied {
+		if l.parameters[name] == nil {
+			l.add(l.root, SeverityError, "supplied parameter %q is not declared in the template", name)
+		}
+	}
+}
+
+func (l *linter) lintPreBootstrapOutputs() {
+	outputs := findMappingValue(l.root, "Outputs")
+	if outputs == nil {
+		return
+	}
+	for i := 0; i < len(outputs.Content)-1; i += 2 {
+		key, output := outputs.Content[i], outputs.Content[i+1]
+		if key.Value != outputExecutionPolicyArn && key.Value != outputPermissionBoundaryName {
+			continue
+		}
+		value := findMappingValue(output, "Value")
+		if value == nil {
+			l.add(key, SeverityError, "output %s has no Value", key.Value)
+			continue
+		}
+		if key.Value == outputExecutionPolicyArn {
+			l.checkPolicyARN(value)
+		} else {
+			l.checkPolicyName(value)
+		}
+	}
+}
+
+// checkPolicyARN accepts values that evaluate to one or more comma separated
+// policy ARNs, as cdk bootstrap --cloudformation-execution-policies expects.
+func (l *linter) checkPolicyARN(value *yaml.Node) {
+	fn, arg := intrinsic(value)
+	switch fn {
+	case "":
+		for _, arn := range strings.Split(value.Value, ",") {
+			if !strings.HasPrefix(strings.TrimSpace(arn), "arn:") {
+				l.add(value, SeverityError, "%s must be a policy ARN, got %q", outputExecutionPolicyArn, arn)
+			}
+		}
+	case "Ref":
+		if t := l.resourceType(arg.Value); t != "" && t != "AWS::IAM::ManagedPolicy" {
+			l.add(value, SeverityError, "%s refers to a %s, not a managed policy", outputExecutionPolicyArn, t)
+		}
+	case "Fn::Sub":
+		if s := subString(arg); s != "" && !strings.HasPrefix(s, "arn:") && !strings.HasPrefix(s, "${") {
+			l.add(value, SeverityError, "%s must substitute into a policy ARN, got %q", outputExecutionPolicyArn, s)
+		}
+	}
+}
+
+// checkPolicyName rejects values that evaluate to an ARN, since cdk bootstrap
+// --custom-permissions-boundary takes the policy name.
+func (l *linter) checkPolicyName(value *yaml.Node) {
+	fn, arg := intrinsic(value)
+	switch fn {
+	case "":
+		if strings.HasPrefix(value.Value, "arn:") {
+			l.add(value, SeverityError, "%s must be a policy name, not an ARN", outputPermissionBoundaryName)
+		}
+	case "Ref":
+		if l.resourceType(arg.Value) == "AWS::IAM::ManagedPolicy" {
+			l.add(value, SeverityError,
+				"%s: Ref of a managed policy returns its ARN; use the policy's ManagedPolicyName", outputPermissionBoundaryName)
+		}
+	case "Fn::Sub":
+		if strings.HasPrefix(subString(arg), "arn:") {
+			l.add(value, SeverityError, "%s must be a policy name, not an ARN", outputPermissionBoundaryName)
+		}
+	}
+}
+
+func (l *linter) resourceType(name string) string {
+	resources := findMappingValue(l.root, "Resources")
+	if resources == nil {
+		return ""
+	}
+	resource := findMappingValue(resources, name)
+	if resource == nil {
+		return ""
+	}
+	if typ := findMappingValue(resource, "Type"); typ != nil {
+		return typ.Value
+	}
+	return ""
+}
+
+// intrinsic returns the long-form function name and argument of node, or ""
+// for a plain value.
+func intrinsic(node *yaml.Node) (string, *yaml.Node) {
+	switch node.Tag {
+	case "!Ref":
+		return "Ref", node
+	case "!GetAtt":
+		return "Fn::GetAtt", node
+	case "!Sub":
+		return "Fn::Sub", node
+	}
+	if node.Kind == yaml.MappingNode && len(node.Content) == 2 {
+		fn := node.Content[0].Value
+		if fn == "Ref" || strings.HasPrefix(fn, "Fn::") {
+			return fn, node.Content[1]
+		}
+	}
+	if node.Kind == yaml.ScalarNode && (node.Tag == "" || strings.HasPrefix(node.Tag, "!!")) {
+		return "", node
+	}
+	return "?", node
+}
+
+func subString(arg *yaml.Node) string {
+	if arg.Kind == yaml.SequenceNode && len(arg.Content) > 0 {
+		arg = arg.Content[0]
+	}
+	if arg.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return arg.Value
+}
+
+func scalars(node *yaml.Node) []*yaml.Node {
+	if node.Kind == yaml.ScalarNode {
+		return []*yaml.Node{node}
+	}
+	var out []*yaml.Node
+	for _, c := range node.Content {
+		if c.Kind == yaml.ScalarNode {
+			out = append(out, c)
+		}
+	}
+	return out
+}
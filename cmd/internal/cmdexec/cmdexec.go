@@ -46,6 +46,23 @@ func Output(ctx context.Context, dir, name string, args ...string) (string, erro
 	return string(out), nil
 }
 
+// CombinedOutput runs the command and returns stdout and stderr interleaved,
+// for tools such as cdk that print their results on stderr.
+func CombinedOutput(ctx context.Context, dir, name string, args ...string) (string, error) {
+	if !filepath.IsAbs(dir) {
+		return "", errors.Newf("cmdexec: dir must be absolute, got %q", dir)
+	}
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", wrapErr(dir, name, args, err, string(out))
+	}
+	return string(out), nil
+}
+
 func Run(ctx context.Context, dir, name string, args ...string) error {
 	if !filepath.IsAbs(dir) {
 		return errors.Newf("cmdexec: dir must be absolute, got %q", dir)
@@ -64,6 +81,24 @@ func Run(ctx context.Context, dir, name string, args ...string) error {
 	return nil
 }
 
+// RunTo runs the command with stdout and stderr both written to out.
+func RunTo(ctx context.Context, dir string, out io.Writer, name string, args ...string) error {
+	if !filepath.IsAbs(dir) {
+		return errors.Newf("cmdexec: dir must be absolute, got %q", dir)
+	}
+
+	var stderrBuf bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	cmd.Stdout = out
+	cmd.Stderr = io.MultiWriter(out, &stderrBuf)
+
+	if err := cmd.Run(); err != nil {
+		return wrapErr(dir, name, args, err, stderrBuf.String())
+	}
+	return nil
+}
+
 func wrapErr(dir, name string, args []string, err error, stderr string) error {
 	exitCode := 1
 	var exitErr *exec.ExitError
@@ -81,3 +116,14 @@ func wrapErr(dir, name string, args []string, err error, stderr string) error {
 		Stderr:   stderr,
 	}
 }
+
+// Summary reduces a failed command to the last line of its stderr, which
+// for the aws CLI holds the service error, so it fits in a table cell.
+func Summary(err error) string {
+	var cmdErr *Error
+	if errors.As(err, &cmdErr) && strings.TrimSpace(cmdErr.Stderr) != "" {
+		lines := strings.Split(strings.TrimSpace(cmdErr.Stderr), "\n")
+		return strings.TrimSpace(lines[len(lines)-1])
+	}
+	return err.Error()
+}
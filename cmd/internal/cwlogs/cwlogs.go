@@ -0,0 +1,238 @@
+// Package cwlogs reads CloudWatch Logs from several log groups at once and
+// prints them as one stream ordered by time.
+package cwlogs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/basewarphq/bw/cmd/internal/cmdexec"
+	"github.com/basewarphq/bw/cmd/internal/parallel"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	followInterval = 2 * time.Second
+	// lateWindow is how far back each follow poll looks again, since events
+	// can be ingested a little after their timestamp.
+	lateWindow      = 30 * time.Second
+	pollConcurrency = 8
+)
+
+type Group struct {
+	// Label names the group in the output, e.g. "BackendCorebackLogs".
+	Label   string
+	Name    string
+	Region  string
+	Profile string
+}
+
+type Event struct {
+	ID        string
+	Group     *Group
+	Timestamp time.Time
+	Message   string
+}
+
+type Options struct {
+	Since   time.Duration
+	Follow  bool
+	Pattern string
+}
+
+// FilterEvents returns the events of a log group since start that match
+// the CloudWatch filter pattern, oldest first.
+func FilterEvents(ctx context.Context, g *Group, start time.Time, pattern string) ([]Event, error) {
+	args := []string{
+		"logs", "filter-log-events",
+		"--no-cli-pager",
+		"--region", g.Region,
+		"--log-group-name", g.Name,
+		"--start-time", strconv.FormatInt(start.UnixMilli(), 10),
+		"--output", "json",
+	}
+	if pattern != "" {
+		args = append(args, "--filter-pattern", pattern)
+	}
+	if g.Profile != "" {
+		args = append(args, "--profile", g.Profile)
+	}
+	out, err := cmdexec.Output(ctx, "/", "aws", args...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading log group %s in %s", g.Name, g.Region)
+	}
+
+	var resp struct {
+		Events []struct {
+			EventID   string `json:"eventId"`
+			Timestamp int64  `json:"timestamp"`
+			Message   string `json:"message"`
+		} `json:"events"`
+	}
+	if err := json.Unmarshal([]byte(out), &resp); err != nil {
+		return nil, errors.Wrapf(err, "parsing events of %s", g.Name)
+	}
+	events := make([]Event, 0, len(resp.Events))
+	for _, e := range resp.Events {
+		events = append(events, Event{
+			ID:        e.EventID,
+			Group:     g,
+			Timestamp: time.UnixMilli(e.Timestamp),
+			Message:   e.Message,
+		})
+	}
+	return events, nil
+}
+
+// Tail prints the events of all groups merged by timestamp. With Follow it
+// keeps polling until ctx is done.
+func Tail(ctx context.Context, w io.Writer, groups []*Group, opts Options) error {
+	start := time.Now().Add(-opts.Since)
+	seen := seenEvents{}
+	for {
+		events, err := poll(ctx, groups, start, opts.Pattern)
+		if ctx.Err() != nil {
+			// Interrupted while following.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		fresh := seen.fresh(events)
+		sort.SliceStable(fresh, func(i, j int) bool { return fresh[i].Timestamp.Before(fresh[j].Timestamp) })
+		for _, e := range fresh {
+			if _, err := fmt.Fprintln(w, Format(e)); err != nil {
+				return err
+			}
+			if e.Timestamp.After(start.Add(lateWindow)) {
+				start = e.Timestamp.Add(-lateWindow)
+			}
+		}
+
+		if !opts.Follow {
+			return nil
+		}
+		seen.prune(start)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(followInterval):
+		}
+	}
+}
+
+// seenEvents remembers the events already printed, by ID, with their
+// timestamps so that those before the polling window can be forgotten.
+type seenEvents map[string]time.Time
+
+// fresh returns the events not seen before and marks them as seen.
+func (s seenEvents) fresh(events []Event) []Event {
+	var fresh []Event
+	for _, e := range events {
+		if _, ok := s[e.ID]; !ok {
+			s[e.ID] = e.Timestamp
+			fresh = append(fresh, e)
+		}
+	}
+	return fresh
+}
+
+// prune forgets events before start; polls from start cannot return them
+// again.
+func (s seenEvents) prune(start time.Time) {
+	for id, ts := range s {
+		if ts.Before(start) {
+			delete(s, id)
+		}
+	}
+}
+
+func poll(ctx context.Context, groups []*Group, start time.Time, pattern string) ([]Event, error) {
+	results := make([][]Event, len(groups))
+	errs := make([]error, len(groups))
+	parallel.ForEach(len(groups), pollConcurrency, func(i int) {
+		results[i], errs[i] = FilterEvents(ctx, groups[i], start, pattern)
+	})
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	var all []Event
+	for _, r := range results {
+		all = append(all, r...)
+	}
+	return all, nil
+}
+
+// Format renders an event as one line. JSON messages, as written by Lambda's
+// JSON log format, zap and the gateway access logs, are shown as level,
+// message and the remaining fields; the X-Ray trace id is pulled to the
+// front so access log and function lines of one request line up.
+func Format(e Event) string {
+	prefix := e.Timestamp.UTC().Format("15:04:05.000") + " " + e.Group.Label
+	msg := strings.TrimSpace(e.Message)
+
+	var fields map[string]any
+	if json.Unmarshal([]byte(msg), &fields) != nil {
+		return prefix + " " + msg
+	}
+
+	var b strings.Builder
+	b.WriteString(prefix)
+	if trace := takeString(fields, "xrayTraceId", "traceId", "trace_id", "AWSTraceHeader"); trace != "" {
+		b.WriteString(" [" + trace + "]")
+	}
+	if level := takeString(fields, "level"); level != "" {
+		b.WriteString(" " + strings.ToUpper(level))
+	}
+	if text := takeString(fields, "msg", "message"); text != "" {
+		b.WriteString(" " + text)
+	}
+	// The timestamp is already shown.
+	takeString(fields, "time", "timestamp", "ts")
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		b.WriteString(" " + k + "=" + fieldValue(fields[k]))
+	}
+	return b.String()
+}
+
+// takeString removes the first of keys present in fields and returns its
+// value.
+func takeString(fields map[string]any, keys ...string) string {
+	for _, k := range keys {
+		v, ok := fields[k]
+		if !ok {
+			continue
+		}
+		delete(fields, k)
+		return fieldValue(v)
+	}
+	return ""
+}
+
+func fieldValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case nil:
+		return "null"
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
@@ -0,0 +1,29 @@
+package cwlogs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSeenEvents(t *testing.T) {
+	t.Parallel()
+	base := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+	old := Event{ID: "old", Timestamp: base}
+	recent := Event{ID: "recent", Timestamp: base.Add(time.Minute)}
+
+	seen := seenEvents{}
+	if got := seen.fresh([]Event{old, recent}); len(got) != 2 {
+		t.Fatalf("first poll: got %d fresh events, want 2", len(got))
+	}
+	if got := seen.fresh([]Event{old, recent}); len(got) != 0 {
+		t.Errorf("repeated poll: got %d fresh events, want 0", len(got))
+	}
+
+	seen.prune(base.Add(30 * time.Second))
+	if _, ok := seen["old"]; ok {
+		t.Error("event before the window was not pruned")
+	}
+	if _, ok := seen["recent"]; !ok {
+		t.Error("event inside the window was pruned")
+	}
+}
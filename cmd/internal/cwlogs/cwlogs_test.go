@@ -0,0 +1,47 @@
+package cwlogs_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/basewarphq/bw/cmd/internal/cwlogs"
+)
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+	ts := time.Date(2026, 5, 1, 8, 30, 15, 123_000_000, time.UTC)
+	group := &cwlogs.Group{Label: "BackendCorebackLogs"}
+
+	tests := []struct {
+		name, message, want string
+	}{
+		{
+			name:    "plain text",
+			message: "START RequestId: abc\n",
+			want:    "08:30:15.123 BackendCorebackLogs START RequestId: abc",
+		},
+		{
+			name:    "zap json",
+			message: `{"level":"info","ts":1746088215.1,"msg":"request","method":"GET","count":3}`,
+			want:    "08:30:15.123 BackendCorebackLogs INFO request count=3 method=GET",
+		},
+		{
+			name: "access log",
+			message: `{"requestId":"r-1","httpMethod":"GET","status":"200",` +
+				`"xrayTraceId":"1-6650-abc","resourcePath":"/health"}`,
+			want: "08:30:15.123 BackendCorebackLogs [1-6650-abc] httpMethod=GET requestId=r-1 " +
+				"resourcePath=/health status=200",
+		},
+		{
+			name:    "lambda platform record",
+			message: `{"time":"2026-05-01T08:30:15Z","type":"platform.report","record":{"durationMs":1.5}}`,
+			want:    `08:30:15.123 BackendCorebackLogs record={"durationMs":1.5} type=platform.report`,
+		},
+	}
+	for _, tt := range tests {
+		got := cwlogs.Format(cwlogs.Event{Group: group, Timestamp: ts, Message: tt.message})
+		if got != tt.want {
+			t.Errorf("%s:\ngot  %q\nwant %q", tt.name, got, tt.want)
+		}
+	}
+}
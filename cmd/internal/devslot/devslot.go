@@ -3,16 +3,20 @@ package devslot
 import (
 	"context"
 	"crypto/rand"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/basewarphq/bw/cmd/internal/cdkctx"
+	"github.com/basewarphq/bw/cmd/internal/cfnpatch"
 	"github.com/basewarphq/bw/cmd/internal/cmdexec"
+	"github.com/basewarphq/bw/cmd/internal/parallel"
 	"github.com/cockroachdb/errors"
 )
 
@@ -25,8 +29,11 @@ var (
 )
 
 const (
-	keyPrefix     = "dev-slots/"
-	claimFileName = "bw.claim"
+	keyPrefix      = "dev-slots/"
+	claimFileName  = "bw.claim"
+	handoverPrefix = "bwslot1."
+
+	listConcurrency = 8
 )
 
 type ClaimFile struct {
@@ -44,13 +51,30 @@ type LockInfo struct {
 type Store struct {
 	Bucket string
 	Region string
+	// Profile is the AWS profile for the bucket's account; empty uses the
+	// default credentials.
+	Profile string
+	History History
+	Actor   string
 }
 
 func NewStore(bucket, region string) *Store {
 	return &Store{Bucket: bucket, Region: region}
 }
 
+// withProfile appends --profile to aws CLI arguments when profile is set.
+func withProfile(profile string, args ...string) []string {
+	if profile != "" {
+		args = append(args, "--profile", profile)
+	}
+	return args
+}
+
 func (s *Store) Claim(ctx context.Context, slot, token, label string) error {
+	return s.claim(ctx, slot, token, label, ActionClaim)
+}
+
+func (s *Store) claim(ctx context.Context, slot, token, label, action string) error {
 	now := time.Now().UTC().Format(time.RFC3339)
 	lock := LockInfo{
 		Token:     token,
@@ -63,7 +87,7 @@ func (s *Store) Claim(ctx context.Context, slot, token, label string) error {
 		return errors.Wrap(err, "marshaling lock info")
 	}
 
-	out, err := s.putObject(ctx, keyPrefix+slot+".lock", body, true)
+	out, err := s.putObject(ctx, lockKey(slot), body, true, true)
 	if err != nil {
 		if strings.Contains(out, "PreconditionFailed") ||
 			strings.Contains(out, "At least one of the pre-conditions") {
@@ -74,28 +98,21 @@ func (s *Store) Claim(ctx context.Context, slot, token, label string) error {
 		}
 		return errors.Newf("claiming slot %s: %s\n%s", slot, err, out)
 	}
+	s.record(ctx, Event{Slot: slot, Action: action, Label: label})
 	return nil
 }
 
 func (s *Store) Release(ctx context.Context, slot, token string) error {
-	lock, err := s.GetLock(ctx, slot)
+	lock, err := s.ownedLock(ctx, slot, token)
 	if err != nil {
 		return err
 	}
-	if lock == nil {
-		return errors.Mark(
-			errors.Newf("slot %s is not claimed", slot),
-			ErrSlotNotClaimed,
-		)
-	}
-	if lock.Token != token {
-		return errors.Mark(
-			errors.Newf("slot %s is claimed by someone else", slot),
-			ErrTokenMismatch,
-		)
-	}
 
-	return s.deleteLock(ctx, slot)
+	if err := s.deleteLock(ctx, slot); err != nil {
+		return err
+	}
+	s.record(ctx, Event{Slot: slot, Action: ActionRelease, Label: lock.Label})
+	return nil
 }
 
 func (s *Store) ForceRelease(ctx context.Context, slot string) error {
@@ -110,7 +127,11 @@ func (s *Store) ForceRelease(ctx context.Context, slot string) error {
 		)
 	}
 
-	return s.deleteLock(ctx, slot)
+	if err := s.deleteLock(ctx, slot); err != nil {
+		return err
+	}
+	s.record(ctx, Event{Slot: slot, Action: ActionForceRelease, PreviousLabel: lock.Label})
+	return nil
 }
 
 func (s *Store) Touch(ctx context.Context, slot, token string) error {
@@ -123,20 +144,44 @@ func (s *Store) Touch(ctx context.Context, slot, token string) error {
 	}
 
 	lock.LastUsed = time.Now().UTC().Format(time.RFC3339)
-	body, err := json.Marshal(lock)
+	return s.writeLock(ctx, slot, lock, "touching")
+}
+
+func (s *Store) Relabel(ctx context.Context, slot, token, label string) error {
+	lock, err := s.ownedLock(ctx, slot, token)
 	if err != nil {
-		return errors.Wrap(err, "marshaling lock info")
+		return err
 	}
 
-	out, err := s.putObject(ctx, keyPrefix+slot+".lock", body, false)
+	previous := lock.Label
+	lock.Label = label
+	lock.LastUsed = time.Now().UTC().Format(time.RFC3339)
+	if err := s.writeLock(ctx, slot, lock, "relabeling"); err != nil {
+		return err
+	}
+	s.record(ctx, Event{Slot: slot, Action: ActionRelabel, Label: label, PreviousLabel: previous})
+	return nil
+}
+
+func (s *Store) Adopt(ctx context.Context, slot, oldToken, newToken, label string) error {
+	lock, err := s.ownedLock(ctx, slot, oldToken)
 	if err != nil {
-		return errors.Newf("touching slot %s: %s\n%s", slot, err, out)
+		return err
+	}
+
+	previous := lock.Label
+	lock.Token = newToken
+	lock.Label = label
+	lock.LastUsed = time.Now().UTC().Format(time.RFC3339)
+	if err := s.writeLock(ctx, slot, lock, "adopting"); err != nil {
+		return err
 	}
+	s.record(ctx, Event{Slot: slot, Action: ActionAdopt, Label: label, PreviousLabel: previous})
 	return nil
 }
 
 func (s *Store) GetLock(ctx context.Context, slot string) (*LockInfo, error) {
-	key := keyPrefix + slot + ".lock"
+	key := lockKey(slot)
 
 	tmpFile, err := os.CreateTemp("", "devslot-*.json")
 	if err != nil {
@@ -146,15 +191,19 @@ func (s *Store) GetLock(ctx context.Context, slot string) (*LockInfo, error) {
 	tmpFile.Close()
 	defer os.Remove(tmpPath)
 
-	_, err = cmdexec.Output(ctx, "/", "aws", "s3api", "get-object",
+	args := withProfile(s.Profile, "s3api", "get-object",
 		"--bucket", s.Bucket,
 		"--key", key,
 		"--region", s.Region,
 		"--no-cli-pager",
-		tmpPath,
 	)
+	_, err = cmdexec.Output(ctx, "/", "aws", append(args, tmpPath)...)
 	if err != nil {
-		return nil, nil //nolint:nilnil // nil means "not claimed"
+		var cmdErr *cmdexec.Error
+		if errors.As(err, &cmdErr) && strings.Contains(cmdErr.Stderr, "NoSuchKey") {
+			return nil, nil //nolint:nilnil // nil means "not claimed"
+		}
+		return nil, errors.Wrapf(err, "reading lock for slot %s", slot)
 	}
 
 	data, err := os.ReadFile(tmpPath)
@@ -169,33 +218,109 @@ func (s *Store) GetLock(ctx context.Context, slot string) (*LockInfo, error) {
 	return &info, nil
 }
 
-func (s *Store) ListAll(ctx context.Context, slots []string) (map[string]*LockInfo, error) {
-	result := make(map[string]*LockInfo, len(slots))
-	for _, slot := range slots {
-		info, err := s.GetLock(ctx, slot)
-		if err != nil {
-			return nil, err
+type SlotLock struct {
+	Slot string
+	Lock *LockInfo
+	Err  error
+}
+
+func (s *Store) ListAll(ctx context.Context, slots []string) ([]SlotLock, error) {
+	keys, err := s.listLockKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]SlotLock, len(slots))
+	parallel.ForEach(len(slots), listConcurrency, func(i int) {
+		result[i].Slot = slots[i]
+		if !keys[lockKey(slots[i])] {
+			return
 		}
-		result[slot] = info
+		result[i].Lock, result[i].Err = s.GetLock(ctx, slots[i])
+	})
+	return result, nil
+}
+
+func (s *Store) listLockKeys(ctx context.Context) (map[string]bool, error) {
+	out, err := cmdexec.Output(ctx, "/", "aws", withProfile(s.Profile, "s3api", "list-objects-v2",
+		"--bucket", s.Bucket,
+		"--prefix", keyPrefix,
+		"--delimiter", "/",
+		"--query", "Contents[].Key",
+		"--output", "json",
+		"--region", s.Region,
+		"--no-cli-pager",
+	)...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing slot locks in %s", s.Bucket)
+	}
+
+	var keys []string
+	if err := json.Unmarshal([]byte(out), &keys); err != nil {
+		return nil, errors.Wrap(err, "parsing slot lock listing")
+	}
+
+	result := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		result[key] = true
 	}
 	return result, nil
 }
 
+func (s *Store) ownedLock(ctx context.Context, slot, token string) (*LockInfo, error) {
+	lock, err := s.GetLock(ctx, slot)
+	if err != nil {
+		return nil, err
+	}
+	if lock == nil {
+		return nil, errors.Mark(
+			errors.Newf("slot %s is not claimed", slot),
+			ErrSlotNotClaimed,
+		)
+	}
+	if lock.Token != token {
+		return nil, errors.Mark(
+			errors.Newf("slot %s is claimed by someone else", slot),
+			ErrTokenMismatch,
+		)
+	}
+	return lock, nil
+}
+
+func (s *Store) writeLock(ctx context.Context, slot string, lock *LockInfo, action string) error {
+	body, err := json.Marshal(lock)
+	if err != nil {
+		return errors.Wrap(err, "marshaling lock info")
+	}
+
+	out, err := s.putObject(ctx, lockKey(slot), body, false, true)
+	if err != nil {
+		return errors.Newf("%s slot %s: %s\n%s", action, slot, err, out)
+	}
+	return nil
+}
+
+func lockKey(slot string) string {
+	return keyPrefix + slot + ".lock"
+}
+
 func (s *Store) deleteLock(ctx context.Context, slot string) error {
-	_, err := cmdexec.Output(ctx, "/", "aws", "s3api", "delete-object",
+	_, err := cmdexec.Output(ctx, "/", "aws", withProfile(s.Profile, "s3api", "delete-object",
 		"--bucket", s.Bucket,
-		"--key", keyPrefix+slot+".lock",
+		"--key", lockKey(slot),
 		"--region", s.Region,
 		"--no-cli-pager",
-	)
+	)...)
 	if err != nil {
 		return errors.Wrapf(err, "deleting lock for slot %s", slot)
 	}
 	return nil
 }
 
+// putObject writes an object. Lock objects carry the tag that the bootstrap
+// bucket's lifecycle rule expires.
 func (s *Store) putObject(
-	ctx context.Context, key string, body []byte, ifNoneMatch bool,
+	ctx context.Context, key string, body []byte, ifNoneMatch, lock bool,
 ) (string, error) {
 	tmpFile, err := os.CreateTemp("", "devslot-body-*.json")
 	if err != nil {
@@ -221,6 +346,10 @@ func (s *Store) putObject(
 	if ifNoneMatch {
 		args = append(args, "--if-none-match", "*")
 	}
+	if lock {
+		args = append(args, "--tagging", cfnpatch.DevSlotLockTagKey+"="+cfnpatch.DevSlotLockTagValue)
+	}
+	args = withProfile(s.Profile, args...)
 
 	out, err := cmdexec.Output(ctx, "/", "aws", args...)
 	if err != nil {
@@ -233,40 +362,132 @@ func (s *Store) putObject(
 	return out, nil
 }
 
-func EnsureClaim(ctx context.Context, dir, profile string) (*ClaimFile, error) {
+type ClaimOptions struct {
+	Slot  string
+	Label string
+}
+
+type Settings struct {
+	Profile string
+	// AccountProfiles maps account IDs to profiles, for the slot locks of
+	// apps that map deployments to accounts.
+	AccountProfiles map[string]string
+	History         string
+	Pools           map[string]string
+	Pool            string
+}
+
+// AccountProfile returns the profile for an account: its entry in
+// AccountProfiles, or Profile when there is none.
+func (s Settings) AccountProfile(account string) string {
+	if p, ok := s.AccountProfiles[account]; ok && account != "" {
+		return p
+	}
+	return s.Profile
+}
+
+// OpenStore opens the lock store for slot, the slot about to be acted on.
+// An empty slot opens the store of the pool, for claims that pick a slot.
+func OpenStore(ctx context.Context, dir string, settings Settings, slot string) (*Store, *cdkctx.CDKContext, error) {
+	cctx, err := cdkctx.Load(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	accountID, profile, err := storeAccount(ctx, settings, cctx, slot)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	store := NewStore(cctx.BootstrapBucket(accountID), cctx.PrimaryRegion)
+	store.Profile = profile
+	store.History, err = NewHistory(settings.History, store.Bucket, store.Region)
+	if err != nil {
+		return nil, nil, err
+	}
+	if h, ok := store.History.(*S3History); ok {
+		h.Profile = profile
+	}
+	store.Actor = DefaultLabel(ctx)
+	return store, cctx, nil
+}
+
+// storeAccount returns the account whose bootstrap bucket holds the lock
+// of slot, and the profile to reach it with: the account the slot deploys
+// to when the app maps deployments to accounts, and the profile's account
+// otherwise. Without a slot, the pool's first slot stands in.
+func storeAccount(
+	ctx context.Context, settings Settings, cctx *cdkctx.CDKContext, slot string,
+) (account, profile string, err error) {
+	if slot == "" {
+		if slots, poolErr := settings.PoolSlots(cctx); poolErr == nil && len(slots) > 0 {
+			slot = slots[0]
+		}
+	}
+	if slot != "" {
+		account = cctx.DeploymentAccount(slot)
+	}
+	profile = settings.AccountProfile(account)
+	if account == "" {
+		account, err = AccountID(ctx, profile)
+	}
+	return account, profile, err
+}
+
+func EnsureClaim(ctx context.Context, dir string, settings Settings, opts ClaimOptions) (*ClaimFile, error) {
 	claim, err := ReadClaimFile(dir)
 	if err != nil && !errors.Is(err, ErrNoClaim) {
 		return nil, err
 	}
 	if claim != nil {
-		TouchClaim(ctx, dir, profile, claim)
+		if opts.Slot != "" && opts.Slot != claim.Slot {
+			return nil, errors.Newf(
+				"this checkout already holds %s; release it before claiming %s", claim.Slot, opts.Slot,
+			)
+		}
+		if opts.Label != "" {
+			store, _, err := OpenStore(ctx, dir, settings, claim.Slot)
+			if err != nil {
+				return nil, err
+			}
+			if err := store.Relabel(ctx, claim.Slot, claim.Token, opts.Label); err != nil {
+				return nil, err
+			}
+			return claim, nil
+		}
+		TouchClaim(ctx, dir, settings, claim)
 		return claim, nil
 	}
 
-	cctx, err := cdkctx.Load(dir)
+	store, cctx, err := OpenStore(ctx, dir, settings, opts.Slot)
 	if err != nil {
 		return nil, err
 	}
 
-	slots := cctx.DevSlots()
-	if len(slots) == 0 {
-		return nil, errors.New("no Dev* deployments defined in cdk.context.json")
-	}
-
-	token, err := GenerateToken()
+	slots, err := settings.PoolSlots(cctx)
 	if err != nil {
 		return nil, err
 	}
+	if opts.Slot != "" && !slices.Contains(slots, opts.Slot) {
+		return nil, errors.Newf("slot %q is not in pool %q, expected one of: %s",
+			opts.Slot, settings.PoolName(), strings.Join(slots, ", "))
+	}
 
-	accountID, err := AccountID(ctx, profile)
+	token, err := GenerateToken()
 	if err != nil {
 		return nil, err
 	}
 
-	store := NewStore(cctx.BootstrapBucket(accountID), cctx.PrimaryRegion)
-	label := DefaultLabel(ctx)
+	label := opts.Label
+	if label == "" {
+		label = DefaultLabel(ctx)
+	}
 
-	slot, err := ClaimFirstAvailable(ctx, store, slots, token, label)
+	slot := opts.Slot
+	if slot != "" {
+		err = store.Claim(ctx, slot, token, label)
+	} else {
+		slot, err = ClaimFirstAvailable(ctx, store, slots, token, label)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -278,19 +499,235 @@ func EnsureClaim(ctx context.Context, dir, profile string) (*ClaimFile, error) {
 	return claim, nil
 }
 
-func TouchClaim(ctx context.Context, dir, profile string, claim *ClaimFile) {
-	cctx, err := cdkctx.Load(dir)
+// BranchLabel is the lock label of a slot claimed for branch by the branch
+// dev strategy.
+func BranchLabel(branch string) string {
+	return "branch:" + branch
+}
+
+// ClaimBranchSlot claims slot for branch, which the branch dev strategy
+// mapped onto it. Checkouts of the same branch share the slot, so a lock
+// labeled for branch is accepted as is. A slot claimed by hand or for
+// another branch is refused, so colliding branches never deploy over each
+// other's stacks.
+func ClaimBranchSlot(ctx context.Context, dir string, settings Settings, slot, branch string) error {
+	claim, err := ReadClaimFile(dir)
+	if err != nil && !errors.Is(err, ErrNoClaim) {
+		return err
+	}
+	if claim != nil {
+		if claim.Slot != slot {
+			return errors.Newf("branch %s maps to slot %s, but this checkout holds %s; release it first",
+				branch, slot, claim.Slot)
+		}
+		TouchClaim(ctx, dir, settings, claim)
+		return nil
+	}
+
+	store, _, err := OpenStore(ctx, dir, settings, slot)
+	if err != nil {
+		return err
+	}
+	lock, err := store.GetLock(ctx, slot)
+	if err != nil {
+		return err
+	}
+	if lock != nil {
+		if lock.Label == BranchLabel(branch) {
+			// Another checkout of the same branch holds the slot; share its
+			// token so deploys from here keep the lock alive too.
+			return WriteClaimFile(dir, &ClaimFile{Slot: slot, Token: lock.Token})
+		}
+		return errors.Mark(
+			errors.Newf("branch %s maps to slot %s, which is claimed by %s", branch, slot, lock.Label),
+			ErrSlotTaken,
+		)
+	}
+
+	token, err := GenerateToken()
+	if err != nil {
+		return err
+	}
+	if err := store.Claim(ctx, slot, token, BranchLabel(branch)); err != nil {
+		return err
+	}
+	return WriteClaimFile(dir, &ClaimFile{Slot: slot, Token: token})
+}
+
+func TouchClaim(ctx context.Context, dir string, settings Settings, claim *ClaimFile) {
+	store, _, err := OpenStore(ctx, dir, settings, claim.Slot)
 	if err != nil {
 		return
 	}
-	accountID, err := AccountID(ctx, profile)
+	lock, err := store.GetLock(ctx, claim.Slot)
 	if err != nil {
 		return
 	}
-	store := NewStore(cctx.BootstrapBucket(accountID), cctx.PrimaryRegion)
+	if lock == nil {
+		// The lock expired through the bucket lifecycle rule while this
+		// checkout kept its claim file; take the slot back if it is still free.
+		_ = store.claim(ctx, claim.Slot, claim.Token, DefaultLabel(ctx), ActionReclaim)
+		return
+	}
 	_ = store.Touch(ctx, claim.Slot, claim.Token)
 }
 
+func RecordDeploy(ctx context.Context, dir string, settings Settings, deployment string, deployErr error) {
+	if !settings.IsSlot(deployment) {
+		return
+	}
+	store, cctx, err := OpenStore(ctx, dir, settings, deployment)
+	if err != nil || !cctx.IsValidDeployment(deployment) {
+		return
+	}
+
+	lock, err := store.GetLock(ctx, deployment)
+	if err != nil {
+		return
+	}
+	ev := Event{Slot: deployment, Action: ActionDeploy}
+	if deployErr != nil {
+		ev.Action = ActionDeployFailed
+	}
+	if lock != nil {
+		ev.Label = lock.Label
+		if claim, _ := ReadClaimFile(dir); claim != nil && claim.Slot == deployment {
+			_ = store.Touch(ctx, claim.Slot, claim.Token)
+		}
+	}
+	store.record(ctx, ev)
+}
+
+// ReleaseDestroyed frees the slot of a destroyed deployment, since there are
+// no stacks left to hold it for. The lock is deleted regardless of holder,
+// and so is this checkout's claim file if it was for the slot.
+func ReleaseDestroyed(ctx context.Context, dir string, settings Settings, deployment string) error {
+	if !settings.IsSlot(deployment) {
+		return nil
+	}
+	store, _, err := OpenStore(ctx, dir, settings, deployment)
+	if err != nil {
+		return err
+	}
+
+	lock, err := store.GetLock(ctx, deployment)
+	if err != nil {
+		return err
+	}
+	ev := Event{Slot: deployment, Action: ActionDestroy}
+	if lock != nil {
+		if err := store.deleteLock(ctx, deployment); err != nil {
+			return err
+		}
+		ev.PreviousLabel = lock.Label
+	}
+	store.record(ctx, ev)
+
+	if claim, _ := ReadClaimFile(dir); claim != nil && claim.Slot == deployment {
+		return RemoveClaimFile(dir)
+	}
+	return nil
+}
+
+func Handover(ctx context.Context, dir string, settings Settings, slot string) (string, error) {
+	claim, err := ReadClaimFile(dir)
+	if err != nil {
+		return "", err
+	}
+	if claim.Slot != slot {
+		return "", errors.Newf("slot %s is not this checkout's claim (holding %s)", slot, claim.Slot)
+	}
+
+	store, _, err := OpenStore(ctx, dir, settings, claim.Slot)
+	if err != nil {
+		return "", err
+	}
+	lock, err := store.ownedLock(ctx, claim.Slot, claim.Token)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := EncodeHandover(claim)
+	if err != nil {
+		return "", err
+	}
+	if err := RemoveClaimFile(dir); err != nil {
+		return "", err
+	}
+	store.record(ctx, Event{Slot: claim.Slot, Action: ActionHandover, Label: lock.Label})
+	return token, nil
+}
+
+func Adopt(ctx context.Context, dir string, settings Settings, handoverToken, label string) (*ClaimFile, error) {
+	handed, err := DecodeHandover(handoverToken)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := ReadClaimFile(dir)
+	if err != nil && !errors.Is(err, ErrNoClaim) {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.Newf(
+			"this checkout already holds %s; release it before adopting %s", existing.Slot, handed.Slot,
+		)
+	}
+
+	store, _, err := OpenStore(ctx, dir, settings, handed.Slot)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := GenerateToken()
+	if err != nil {
+		return nil, err
+	}
+	if label == "" {
+		label = DefaultLabel(ctx)
+	}
+
+	// Rotating the token makes a handover token single-use: once adopted,
+	// the machine that printed it can no longer act on the slot.
+	if err := store.Adopt(ctx, handed.Slot, handed.Token, token, label); err != nil {
+		return nil, err
+	}
+
+	claim := &ClaimFile{Slot: handed.Slot, Token: token}
+	if err := WriteClaimFile(dir, claim); err != nil {
+		return nil, err
+	}
+	return claim, nil
+}
+
+func EncodeHandover(claim *ClaimFile) (string, error) {
+	data, err := json.Marshal(claim)
+	if err != nil {
+		return "", errors.Wrap(err, "marshaling handover token")
+	}
+	return handoverPrefix + base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func DecodeHandover(s string) (*ClaimFile, error) {
+	encoded, ok := strings.CutPrefix(strings.TrimSpace(s), handoverPrefix)
+	if !ok {
+		return nil, errors.Newf("handover token must start with %q", handoverPrefix)
+	}
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding handover token")
+	}
+
+	var claim ClaimFile
+	if err := json.Unmarshal(data, &claim); err != nil {
+		return nil, errors.Wrap(err, "parsing handover token")
+	}
+	if claim.Slot == "" || claim.Token == "" {
+		return nil, errors.New("handover token is missing slot or token")
+	}
+	return &claim, nil
+}
+
 func ClaimFirstAvailable(
 	ctx context.Context, store *Store, slots []string, token, label string,
 ) (string, error) {
@@ -0,0 +1,392 @@
+package devslot_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/basewarphq/bw/cmd/internal/cdkctx"
+	"github.com/basewarphq/bw/cmd/internal/devslot"
+	"github.com/basewarphq/bw/cmd/internal/testutil"
+	"github.com/cockroachdb/errors"
+)
+
+func TestHandoverRoundTrip(t *testing.T) {
+	t.Parallel()
+	claim := &devslot.ClaimFile{Slot: "Dev05", Token: "abc123"}
+
+	encoded, err := devslot.EncodeHandover(claim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(encoded, "bwslot1.") {
+		t.Errorf("token should be versioned, got %q", encoded)
+	}
+
+	got, err := devslot.DecodeHandover("  " + encoded + "\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *got != *claim {
+		t.Errorf("got %+v, want %+v", got, claim)
+	}
+}
+
+func TestDecodeHandover_Invalid(t *testing.T) {
+	t.Parallel()
+	for name, token := range map[string]string{
+		"no prefix":     "eyJzbG90IjoiRGV2MDUifQ",
+		"bad base64":    "bwslot1.!!!",
+		"bad json":      "bwslot1.bm90LWpzb24",
+		"missing token": "bwslot1.eyJzbG90IjoiRGV2MDUifQ",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := devslot.DecodeHandover(token); err == nil {
+				t.Errorf("expected error for %q", token)
+			}
+		})
+	}
+}
+
+func TestNewHistory(t *testing.T) {
+	t.Parallel()
+
+	h, err := devslot.NewHistory("", "cdk-bwapp-assets-123-eu-central-1", "eu-central-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s3h, ok := h.(*devslot.S3History)
+	if !ok {
+		t.Fatalf("expected *S3History, got %T", h)
+	}
+	if s3h.Bucket != "cdk-bwapp-assets-123-eu-central-1" || s3h.Prefix != "dev-slots/history/" {
+		t.Errorf("default history = %+v", s3h)
+	}
+
+	h, err = devslot.NewHistory("s3://audit/bw/slots", "ignored", "eu-west-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s3h = h.(*devslot.S3History)
+	if s3h.Bucket != "audit" || s3h.Prefix != "bw/slots/" || s3h.Region != "eu-west-1" {
+		t.Errorf("custom history = %+v", s3h)
+	}
+
+	h, err = devslot.NewHistory("none", "bucket", "eu-west-1")
+	if err != nil || h != nil {
+		t.Errorf("expected disabled history, got %v, %v", h, err)
+	}
+
+	for _, bad := range []string{"s3://", "file:///tmp/history"} {
+		if _, err := devslot.NewHistory(bad, "bucket", "eu-west-1"); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestValidatePools(t *testing.T) {
+	t.Parallel()
+
+	if err := devslot.ValidatePools(nil, ""); err != nil {
+		t.Errorf("no pools should be valid, got %v", err)
+	}
+	if err := devslot.ValidatePools(map[string]string{"dev": "Dev*", "preview": "Pr*"}, "preview"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := devslot.ValidatePools(map[string]string{"preview": "Pr*"}, ""); err == nil {
+		t.Error("expected error when the default pool is not defined")
+	}
+	if err := devslot.ValidatePools(map[string]string{"dev": "Dev["}, ""); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
+
+func TestSettingsIsSlot(t *testing.T) {
+	t.Parallel()
+
+	var defaults devslot.Settings
+	if !defaults.IsSlot("Dev03") || defaults.IsSlot("Prod") {
+		t.Error("default pool should match Dev* only")
+	}
+
+	custom := devslot.Settings{Pools: map[string]string{"preview": "Pr*"}}
+	if !custom.IsSlot("Pr01") || custom.IsSlot("Dev03") {
+		t.Error("configured pools should replace the default pool")
+	}
+	if custom.IsSlot("Prod") {
+		t.Error("Prod must never be a slot")
+	}
+}
+
+func TestSettingsPoolSlots_ExcludesProd(t *testing.T) {
+	t.Parallel()
+	dir := testutil.Setup(t, map[string]string{
+		"cdk.json": `{"context": {"@aws-cdk/core:bootstrapQualifier": "bwapp"}}`,
+		"cdk.context.json": `{
+			"bwapp-primary-region": "eu-central-1",
+			"bwapp-deployments": ["Prod", "Pr01", "Pr02", "Dev01"]
+		}`,
+	})
+	cctx, err := cdkctx.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	settings := devslot.Settings{Pools: map[string]string{"dev": "Dev*", "preview": "Pr*"}, Pool: "preview"}
+	got, err := settings.PoolSlots(cctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != "Pr01" || got[1] != "Pr02" {
+		t.Errorf("got %v, want [Pr01 Pr02]", got)
+	}
+
+	settings.Pool = "missing"
+	if _, err := settings.PoolSlots(cctx); err == nil {
+		t.Error("expected error for unknown pool")
+	}
+}
+
+// fakeAWSScript stands in for the aws CLI. Objects live in files under
+// $FAKE_AWS_ROOT/s3/<bucket>/<key>, and a bucket is only reachable with a
+// profile of the account in its name, like cdk bootstrap buckets.
+const fakeAWSScript = `#!/bin/sh
+echo "$*" >> "$FAKE_AWS_ROOT/calls.log"
+cmd="$1 $2"
+for last; do :; done
+profile=default bucket= key= body= ifnonematch=
+if [ "$cmd" = "s3 cp" ]; then
+	src=${3#s3://} dst=$4
+	bucket=${src%%/*} key=${src#*/}
+fi
+while [ $# -gt 0 ]; do
+	case $1 in
+	--profile) profile=$2; shift ;;
+	--bucket) bucket=$2; shift ;;
+	--key) key=$2; shift ;;
+	--body) body=$2; shift ;;
+	--if-none-match) ifnonematch=1; shift ;;
+	esac
+	shift
+done
+account=$(cat "$FAKE_AWS_ROOT/profiles/$profile" 2>/dev/null) || {
+	echo "The config profile ($profile) could not be found" >&2; exit 255
+}
+if [ "$cmd" = "sts get-caller-identity" ]; then echo "$account"; exit 0; fi
+case $bucket in *-$account-*) ;; *) echo "An error occurred (AccessDenied)" >&2; exit 254 ;; esac
+obj="$FAKE_AWS_ROOT/s3/$bucket/$key"
+case $cmd in
+"s3api get-object")
+	[ -f "$obj" ] || { echo "An error occurred (NoSuchKey)" >&2; exit 254; }
+	cp "$obj" "$last" ;;
+"s3api put-object")
+	if [ -n "$ifnonematch" ] && [ -f "$obj" ]; then
+		echo "An error occurred (PreconditionFailed)" >&2; exit 254
+	fi
+	mkdir -p "$(dirname "$obj")" && cp "$body" "$obj" ;;
+"s3api delete-object") rm -f "$obj" ;;
+"s3 cp")
+	if [ -d "$obj" ]; then cp -R "$obj/." "$dst"; fi ;;
+*) echo "fake aws: unsupported command $cmd" >&2; exit 1 ;;
+esac
+`
+
+// fakeAWS puts fakeAWSScript first on PATH. profiles maps profile names to
+// their account; "default" is used when no --profile is passed.
+func fakeAWS(t *testing.T, profiles map[string]string) {
+	t.Helper()
+	root := t.TempDir()
+	bin := filepath.Join(root, "bin")
+	if err := os.MkdirAll(filepath.Join(root, "profiles"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(bin, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for profile, account := range profiles {
+		if err := os.WriteFile(filepath.Join(root, "profiles", profile), []byte(account), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(bin, "aws"), []byte(fakeAWSScript), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("FAKE_AWS_ROOT", root)
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func slotProject(t *testing.T, extraContext string) string {
+	t.Helper()
+	return testutil.Setup(t, map[string]string{
+		"cdk.json": `{"context": {"@aws-cdk/core:bootstrapQualifier": "bwapp"}}`,
+		"cdk.context.json": `{
+			"bwapp-primary-region": "eu-central-1",` + extraContext + `
+			"bwapp-deployments": ["Prod", "Pr01", "Pr02"]
+		}`,
+	})
+}
+
+func TestOpenStore_SlotAccount(t *testing.T) { //nolint:paralleltest // fakeAWS changes PATH
+	fakeAWS(t, map[string]string{"default": "111111111111", "preview": "222222222222"})
+	ctx := t.Context()
+	dir := testutil.Setup(t, map[string]string{
+		"cdk.json": `{"context": {"@aws-cdk/core:bootstrapQualifier": "bwapp"}}`,
+		"cdk.context.json": `{
+			"bwapp-primary-region": "eu-central-1",
+			"bwapp-deployment-accounts": {"Dev*": "111111111111", "Pr*": "222222222222"},
+			"bwapp-deployments": ["Prod", "Dev1", "Pr01"]
+		}`,
+	})
+	// The default pool deploys to another account than the slot acted on,
+	// as it does for deploys and destroys, which never select a pool.
+	settings := devslot.Settings{
+		History:         "none",
+		AccountProfiles: map[string]string{"222222222222": "preview"},
+		Pools:           map[string]string{"dev": "Dev*", "preview": "Pr*"},
+	}
+
+	if err := devslot.ClaimBranchSlot(ctx, dir, settings, "Pr01", "feature/a"); err != nil {
+		t.Fatal(err)
+	}
+	devslot.RecordDeploy(ctx, dir, settings, "Pr01", nil)
+
+	store, _, err := devslot.OpenStore(ctx, dir, settings, "Pr01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.Bucket != "cdk-bwapp-assets-222222222222-eu-central-1" || store.Profile != "preview" {
+		t.Errorf("store: got bucket %q profile %q", store.Bucket, store.Profile)
+	}
+	if lock, err := store.GetLock(ctx, "Pr01"); err != nil || lock == nil || lock.Label != devslot.BranchLabel("feature/a") {
+		t.Fatalf("lock in the slot's account: got %+v, %v", lock, err)
+	}
+
+	pool, _, err := devslot.OpenStore(ctx, dir, settings, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pool.Bucket != "cdk-bwapp-assets-111111111111-eu-central-1" {
+		t.Errorf("default pool store: got bucket %q", pool.Bucket)
+	}
+
+	if err := devslot.ReleaseDestroyed(ctx, dir, settings, "Pr01"); err != nil {
+		t.Fatal(err)
+	}
+	if lock, err := store.GetLock(ctx, "Pr01"); err != nil || lock != nil {
+		t.Errorf("lock after destroy: got %+v, %v, want released", lock, err)
+	}
+}
+
+func TestClaimBranchSlot(t *testing.T) { //nolint:paralleltest // fakeAWS changes PATH
+	fakeAWS(t, map[string]string{"default": "111111111111"})
+	ctx := t.Context()
+	settings := devslot.Settings{History: "none", Pools: map[string]string{"preview": "Pr*"}, Pool: "preview"}
+
+	first := slotProject(t, "")
+	if err := devslot.ClaimBranchSlot(ctx, first, settings, "Pr01", "feature/a"); err != nil {
+		t.Fatal(err)
+	}
+	claim, err := devslot.ReadClaimFile(first)
+	if err != nil || claim.Slot != "Pr01" {
+		t.Fatalf("claim file: got %+v, %v", claim, err)
+	}
+
+	// Another checkout of the same branch shares the slot.
+	second := slotProject(t, "")
+	if err := devslot.ClaimBranchSlot(ctx, second, settings, "Pr01", "feature/a"); err != nil {
+		t.Errorf("same branch: %v", err)
+	}
+	shared, err := devslot.ReadClaimFile(second)
+	if err != nil || *shared != *claim {
+		t.Errorf("shared claim file: got %+v, %v, want %+v", shared, err, claim)
+	}
+
+	// A branch that collides onto the claimed slot is refused.
+	err = devslot.ClaimBranchSlot(ctx, slotProject(t, ""), settings, "Pr01", "feature/b")
+	if !errors.Is(err, devslot.ErrSlotTaken) {
+		t.Errorf("colliding branch: got %v, want ErrSlotTaken", err)
+	}
+
+	// A checkout holding a slot does not silently move to another one.
+	if err := devslot.ClaimBranchSlot(ctx, first, settings, "Pr02", "feature/c"); err == nil {
+		t.Error("expected error when the checkout already holds another slot")
+	}
+}
+
+func TestReleaseDestroyed(t *testing.T) { //nolint:paralleltest // fakeAWS changes PATH
+	fakeAWS(t, map[string]string{"default": "111111111111"})
+	ctx := t.Context()
+	settings := devslot.Settings{History: "none", Pools: map[string]string{"preview": "Pr*"}, Pool: "preview"}
+	dir := slotProject(t, "")
+
+	claim, err := devslot.EnsureClaim(ctx, dir, settings, devslot.ClaimOptions{Slot: "Pr01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := devslot.ReleaseDestroyed(ctx, dir, settings, claim.Slot); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := devslot.ReadClaimFile(dir); !errors.Is(err, devslot.ErrNoClaim) {
+		t.Errorf("claim file: got %v, want ErrNoClaim", err)
+	}
+
+	store, _, err := devslot.OpenStore(ctx, dir, settings, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lock, err := store.GetLock(ctx, "Pr01"); err != nil || lock != nil {
+		t.Errorf("lock: got %+v, %v, want released", lock, err)
+	}
+
+	// Prod is never a slot, so there is nothing to release.
+	if err := devslot.ReleaseDestroyed(ctx, dir, settings, "Prod"); err != nil {
+		t.Errorf("Prod: %v", err)
+	}
+}
+
+func TestOpenStore_PoolAccount(t *testing.T) { //nolint:paralleltest // fakeAWS changes PATH
+	fakeAWS(t, map[string]string{"default": "111111111111", "dev": "222222222222"})
+	ctx := t.Context()
+	dir := slotProject(t, `
+			"bwapp-deployment-accounts": {"Prod": "111111111111", "Pr*": "222222222222"},`)
+	settings := devslot.Settings{
+		AccountProfiles: map[string]string{"222222222222": "dev"},
+		Pools:           map[string]string{"preview": "Pr*"},
+		Pool:            "preview",
+	}
+
+	store, _, err := devslot.OpenStore(ctx, dir, settings, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.Bucket != "cdk-bwapp-assets-222222222222-eu-central-1" || store.Profile != "dev" {
+		t.Errorf("store: got bucket %q profile %q", store.Bucket, store.Profile)
+	}
+
+	// The default profile's account cannot reach the pool account's bucket;
+	// claim, deploy records and history must all use the mapped profile.
+	claim, err := devslot.EnsureClaim(ctx, dir, settings, devslot.ClaimOptions{Slot: "Pr01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	devslot.RecordDeploy(ctx, dir, settings, claim.Slot, nil)
+	events, err := store.History.List(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].Action != devslot.ActionClaim || events[1].Action != devslot.ActionDeploy {
+		t.Errorf("history: got %+v", events)
+	}
+	if events, err := store.History.List(ctx, []string{"Pr02"}); err != nil || len(events) != 0 {
+		t.Errorf("history of Pr02: got %+v, %v, want none", events, err)
+	}
+
+	settings.AccountProfiles = nil
+	if _, err := devslot.EnsureClaim(ctx, slotProject(t, `
+			"bwapp-deployment-accounts": {"Prod": "111111111111", "Pr*": "222222222222"},`),
+		settings, devslot.ClaimOptions{Slot: "Pr02"}); err == nil {
+		t.Error("expected the default profile to be denied the pool account's bucket")
+	}
+}
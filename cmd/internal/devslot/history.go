@@ -0,0 +1,179 @@
+package devslot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/basewarphq/bw/cmd/internal/cmdexec"
+	"github.com/cockroachdb/errors"
+)
+
+// Events under the default prefix share dev-slots/ with the lock files, but
+// the bootstrap bucket's lifecycle rule only expires objects tagged as locks.
+// Point slots.history at another bucket to keep them apart from the assets.
+const (
+	historyPrefix   = keyPrefix + "history/"
+	historyDisabled = "none"
+)
+
+const (
+	ActionClaim        = "claim"
+	ActionRelease      = "release"
+	ActionForceRelease = "force-release"
+	ActionRelabel      = "relabel"
+	ActionReclaim      = "reclaim"
+	ActionHandover     = "handover"
+	ActionAdopt        = "adopt"
+	ActionDeploy       = "deploy"
+	ActionDeployFailed = "deploy-failed"
+	ActionDestroy      = "destroy"
+)
+
+type Event struct {
+	Time          string `json:"time"`
+	Slot          string `json:"slot"`
+	Action        string `json:"action"`
+	Label         string `json:"label,omitempty"`
+	PreviousLabel string `json:"previous_label,omitempty"`
+	Actor         string `json:"actor"`
+}
+
+type History interface {
+	Append(ctx context.Context, ev Event) error
+	// List returns the events of slots, or of every slot when slots is
+	// empty, oldest first.
+	List(ctx context.Context, slots []string) ([]Event, error)
+}
+
+type S3History struct {
+	Bucket  string
+	Prefix  string
+	Region  string
+	Profile string
+}
+
+func (h *S3History) Append(ctx context.Context, ev Event) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return errors.Wrap(err, "marshaling history event")
+	}
+
+	suffix, err := GenerateToken()
+	if err != nil {
+		return err
+	}
+	ts, err := time.Parse(time.RFC3339Nano, ev.Time)
+	if err != nil {
+		return errors.Wrapf(err, "parsing event time %q", ev.Time)
+	}
+	key := fmt.Sprintf("%s%s/%s-%s-%s.json",
+		h.Prefix, ev.Slot, ts.UTC().Format("20060102T150405.000000000Z"), ev.Action, suffix[:8])
+
+	store := &Store{Bucket: h.Bucket, Region: h.Region, Profile: h.Profile}
+	out, err := store.putObject(ctx, key, body, true, false)
+	if err != nil {
+		return errors.Newf("writing history event %s: %s\n%s", key, err, out)
+	}
+	return nil
+}
+
+// List downloads only the events under the slots' own prefixes, so the
+// timeline of a few slots does not fetch the history of all of them.
+func (h *S3History) List(ctx context.Context, slots []string) ([]Event, error) {
+	tmpDir, err := os.MkdirTemp("", "devslot-history-*")
+	if err != nil {
+		return nil, errors.Wrap(err, "creating temp dir")
+	}
+	defer os.RemoveAll(tmpDir)
+
+	prefixes := []string{""}
+	if len(slots) > 0 {
+		prefixes = make([]string, len(slots))
+		for i, slot := range slots {
+			prefixes[i] = slot + "/"
+		}
+	}
+	for _, prefix := range prefixes {
+		src := "s3://" + h.Bucket + "/" + h.Prefix + prefix
+		_, err = cmdexec.Output(ctx, "/", "aws", withProfile(h.Profile, "s3", "cp", src,
+			filepath.Join(tmpDir, prefix),
+			"--recursive",
+			"--exclude", "*",
+			"--include", "*.json",
+			"--region", h.Region,
+			"--only-show-errors",
+			"--no-cli-pager",
+		)...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "downloading slot history from %s", src)
+		}
+	}
+
+	var events []Event
+	err = filepath.WalkDir(tmpDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".json") {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "reading %s", path)
+		}
+		var ev Event
+		if err := json.Unmarshal(data, &ev); err != nil {
+			return errors.Wrapf(err, "parsing history event %s", path)
+		}
+		events = append(events, ev)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	SortEvents(events)
+	return events, nil
+}
+
+func SortEvents(events []Event) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time < events[j].Time
+	})
+}
+
+func NewHistory(location, defaultBucket, region string) (History, error) {
+	switch {
+	case location == "":
+		return &S3History{Bucket: defaultBucket, Prefix: historyPrefix, Region: region}, nil
+	case location == historyDisabled:
+		return nil, nil //nolint:nilnil // nil means history is disabled
+	case strings.HasPrefix(location, "s3://"):
+		bucket, prefix, _ := strings.Cut(strings.TrimPrefix(location, "s3://"), "/")
+		if bucket == "" {
+			return nil, errors.Newf("history location %q has no bucket", location)
+		}
+		if prefix != "" && !strings.HasSuffix(prefix, "/") {
+			prefix += "/"
+		}
+		return &S3History{Bucket: bucket, Prefix: prefix, Region: region}, nil
+	default:
+		return nil, errors.Newf("history location must be %q or an s3:// URL, got %q", historyDisabled, location)
+	}
+}
+
+func (s *Store) record(ctx context.Context, ev Event) {
+	if s.History == nil {
+		return
+	}
+	ev.Time = time.Now().UTC().Format(time.RFC3339Nano)
+	if ev.Actor == "" {
+		ev.Actor = s.Actor
+	}
+	if err := s.History.Append(ctx, ev); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: recording slot history: %v\n", err)
+	}
+}
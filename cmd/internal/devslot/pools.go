@@ -0,0 +1,91 @@
+package devslot
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/basewarphq/bw/bwcdk/bwcdkutil"
+	"github.com/basewarphq/bw/cmd/internal/cdkctx"
+	"github.com/cockroachdb/errors"
+)
+
+const DefaultPool = "dev"
+
+var defaultPools = map[string]string{DefaultPool: "Dev*"}
+
+func (s Settings) pools() map[string]string {
+	if len(s.Pools) == 0 {
+		return defaultPools
+	}
+	return s.Pools
+}
+
+func (s Settings) PoolName() string {
+	if s.Pool != "" {
+		return s.Pool
+	}
+	return DefaultPool
+}
+
+func (s Settings) PoolSlots(cctx *cdkctx.CDKContext) ([]string, error) {
+	name := s.PoolName()
+	pattern, ok := s.pools()[name]
+	if !ok {
+		return nil, errors.Newf("unknown slot pool %q, expected one of: %s",
+			name, strings.Join(s.PoolNames(), ", "))
+	}
+	var slots []string
+	for _, d := range cctx.DeploymentsMatching(pattern) {
+		if !bwcdkutil.IsProdDeployment(d) {
+			slots = append(slots, d)
+		}
+	}
+	if len(slots) == 0 {
+		return nil, errors.Newf("no deployments in cdk.context.json match pool %q (%s)", name, pattern)
+	}
+	return slots, nil
+}
+
+func (s Settings) PoolNames() []string {
+	names := make([]string, 0, len(s.pools()))
+	for name := range s.pools() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsSlot reports whether the deployment belongs to any pool. Production is
+// never a slot, even when a pattern like "Pr*" happens to match it.
+func (s Settings) IsSlot(deployment string) bool {
+	if bwcdkutil.IsProdDeployment(deployment) {
+		return false
+	}
+	for _, pattern := range s.pools() {
+		if ok, _ := path.Match(pattern, deployment); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func ValidatePools(pools map[string]string, defaultPool string) error {
+	for name, pattern := range pools {
+		if name == "" || pattern == "" {
+			return errors.Newf("slot pool %q must have a name and a pattern", name)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return errors.Newf("slot pool %q has invalid pattern %q", name, pattern)
+		}
+	}
+	if defaultPool == "" {
+		defaultPool = DefaultPool
+	}
+	if len(pools) > 0 {
+		if _, ok := pools[defaultPool]; !ok {
+			return errors.Newf("default slot pool %q is not defined in slots.pools", defaultPool)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package devstrategy
+
+import (
+	"context"
+	"hash/fnv"
+
+	"github.com/basewarphq/bw/cmd/internal/gitinfo"
+	"github.com/cockroachdb/errors"
+)
+
+// BranchDeployment returns the slot that the checked out branch maps onto,
+// and the branch. Callers claim the slot before deploying to it.
+func BranchDeployment(ctx context.Context, dir string, slots []string) (slot, branch string, err error) {
+	branch, err = gitinfo.Branch(ctx, dir)
+	if err != nil {
+		return "", "", errors.Wrap(err, "branch dev strategy")
+	}
+	slot, err = SlotForBranch(branch, slots)
+	return slot, branch, err
+}
+
+// SlotForBranch hashes the branch name onto one of the slots, so every
+// checkout of the same branch deploys to the same slot as long as the pool
+// does not change.
+func SlotForBranch(branch string, slots []string) (string, error) {
+	if len(slots) == 0 {
+		return "", errors.New("no slots to map the branch onto")
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(branch))
+	return slots[h.Sum32()%uint32(len(slots))], nil //nolint:gosec // len(slots) is small
+}
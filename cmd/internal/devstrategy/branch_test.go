@@ -0,0 +1,50 @@
+package devstrategy_test
+
+import (
+	"testing"
+
+	"github.com/basewarphq/bw/cmd/internal/devstrategy"
+)
+
+func TestSlotForBranch_Deterministic(t *testing.T) {
+	t.Parallel()
+	slots := []string{"Pr01", "Pr02", "Pr03", "Pr04"}
+
+	first, err := devstrategy.SlotForBranch("feature/login", slots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 10 {
+		got, err := devstrategy.SlotForBranch("feature/login", slots)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != first {
+			t.Fatalf("got %q, want %q", got, first)
+		}
+	}
+}
+
+func TestSlotForBranch_Spreads(t *testing.T) {
+	t.Parallel()
+	slots := []string{"Pr01", "Pr02", "Pr03", "Pr04"}
+
+	seen := make(map[string]bool)
+	for _, branch := range []string{"main", "feature/a", "feature/b", "fix/c", "chore/d", "release/e"} {
+		got, err := devstrategy.SlotForBranch(branch, slots)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen[got] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("expected branches to spread over several slots, got %v", seen)
+	}
+}
+
+func TestSlotForBranch_EmptyPool(t *testing.T) {
+	t.Parallel()
+	if _, err := devstrategy.SlotForBranch("main", nil); err == nil {
+		t.Fatal("expected error for empty pool")
+	}
+}
@@ -0,0 +1,104 @@
+// Package dnscheck verifies that a domain is delegated to a hosted zone by
+// asking public resolvers for its NS records.
+package dnscheck
+
+import (
+	"context"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/basewarphq/bw/cmd/internal/parallel"
+)
+
+// PublicResolvers are queried directly, bypassing local and corporate DNS
+// that may cache or override the delegation.
+var PublicResolvers = []string{"1.1.1.1:53", "8.8.8.8:53", "9.9.9.9:53"}
+
+const lookupTimeout = 5 * time.Second
+
+type ResolverResult struct {
+	Resolver    string
+	NameServers []string
+	Err         error
+}
+
+// Matches reports whether the resolver returned exactly the expected name
+// servers.
+func (r ResolverResult) Matches(expected []string) bool {
+	return r.Err == nil && slices.Equal(r.NameServers, expected)
+}
+
+type Report struct {
+	Domain   string
+	Expected []string
+	Results  []ResolverResult
+}
+
+// Delegated reports whether every resolver sees the hosted zone's name
+// servers.
+func (r *Report) Delegated() bool {
+	if len(r.Expected) == 0 || len(r.Results) == 0 {
+		return false
+	}
+	for _, res := range r.Results {
+		if !res.Matches(r.Expected) {
+			return false
+		}
+	}
+	return true
+}
+
+// Check looks up the NS records of domain on each resolver and compares them
+// with expected, the name servers of the hosted zone.
+func Check(ctx context.Context, domain string, expected, resolvers []string) *Report {
+	report := &Report{
+		Domain:   domain,
+		Expected: Normalize(expected),
+		Results:  make([]ResolverResult, len(resolvers)),
+	}
+	parallel.ForEach(len(resolvers), len(resolvers), func(i int) {
+		report.Results[i] = lookup(ctx, domain, resolvers[i])
+	})
+	return report
+}
+
+func lookup(ctx context.Context, domain, resolver string) ResolverResult {
+	res := ResolverResult{Resolver: resolver}
+	r := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, resolver)
+		},
+	}
+	ctx, cancel := context.WithTimeout(ctx, lookupTimeout)
+	defer cancel()
+
+	records, err := r.LookupNS(ctx, domain)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	hosts := make([]string, 0, len(records))
+	for _, ns := range records {
+		hosts = append(hosts, ns.Host)
+	}
+	res.NameServers = Normalize(hosts)
+	return res
+}
+
+// Normalize lowercases name servers, drops the trailing root dot and sorts
+// them, so lists from Route 53 and from DNS compare equal.
+func Normalize(hosts []string) []string {
+	out := make([]string, 0, len(hosts))
+	for _, h := range hosts {
+		h = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(h)), ".")
+		if h != "" {
+			out = append(out, h)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
@@ -0,0 +1,45 @@
+package dnscheck_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/basewarphq/bw/cmd/internal/dnscheck"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+	got := dnscheck.Normalize([]string{"NS-2.awsdns-02.NET.", " ns-1.awsdns-01.org", ""})
+	want := []string{"ns-1.awsdns-01.org", "ns-2.awsdns-02.net"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestReportDelegated(t *testing.T) {
+	t.Parallel()
+	expected := []string{"ns-1.awsdns-01.org", "ns-2.awsdns-02.net"}
+
+	report := &dnscheck.Report{Expected: expected, Results: []dnscheck.ResolverResult{
+		{Resolver: "1.1.1.1:53", NameServers: expected},
+		{Resolver: "8.8.8.8:53", NameServers: expected},
+	}}
+	if !report.Delegated() {
+		t.Error("expected delegation to be complete")
+	}
+
+	report.Results[1] = dnscheck.ResolverResult{Resolver: "8.8.8.8:53", NameServers: []string{"ns1.registrar.com"}}
+	if report.Delegated() {
+		t.Error("a resolver with other name servers means delegation is not complete")
+	}
+
+	report.Results[1] = dnscheck.ResolverResult{Resolver: "8.8.8.8:53", Err: errors.New("timeout")}
+	if report.Delegated() {
+		t.Error("a failed lookup must not count as delegated")
+	}
+
+	if (&dnscheck.Report{}).Delegated() {
+		t.Error("an empty report must not count as delegated")
+	}
+}
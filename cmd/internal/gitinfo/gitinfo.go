@@ -0,0 +1,59 @@
+package gitinfo
+
+import (
+	"context"
+	"strings"
+
+	"github.com/basewarphq/bw/cmd/internal/cmdexec"
+	"github.com/cockroachdb/errors"
+)
+
+func Head(ctx context.Context, dir string) (string, error) {
+	out, err := cmdexec.Output(ctx, dir, "git", "rev-parse", "HEAD")
+	if err != nil {
+		return "", errors.Wrap(err, "resolving git HEAD")
+	}
+	return strings.TrimSpace(out), nil
+}
+
+func IsDirty(ctx context.Context, dir string) (bool, error) {
+	out, err := cmdexec.Output(ctx, dir, "git", "status", "--porcelain")
+	if err != nil {
+		return false, errors.Wrap(err, "reading git status")
+	}
+	return strings.TrimSpace(out) != "", nil
+}
+
+// Revision returns HEAD with a "-dirty" suffix when the working tree has
+// uncommitted changes, or "" when dir is not inside a git checkout.
+func Revision(ctx context.Context, dir string) string {
+	head, err := Head(ctx, dir)
+	if err != nil {
+		return ""
+	}
+	if dirty, err := IsDirty(ctx, dir); err == nil && dirty {
+		return head + "-dirty"
+	}
+	return head
+}
+
+func Branch(ctx context.Context, dir string) (string, error) {
+	out, err := cmdexec.Output(ctx, dir, "git", "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", errors.Wrap(err, "resolving current git branch")
+	}
+	branch := strings.TrimSpace(out)
+	if branch == "HEAD" {
+		return "", errors.New("HEAD is detached, not on a branch")
+	}
+	return branch, nil
+}
+
+// Tags returns the tags that point at HEAD.
+func Tags(ctx context.Context, dir string) ([]string, error) {
+	out, err := cmdexec.Output(ctx, dir, "git", "tag", "--points-at", "HEAD")
+	if err != nil {
+		return nil, errors.Wrap(err, "listing git tags at HEAD")
+	}
+	return strings.Fields(out), nil
+}
@@ -0,0 +1,84 @@
+// Package healthcheck calls deployed HTTP endpoints and reports what a
+// post-deploy smoke test cares about: DNS, status, latency and certificate
+// expiry.
+package healthcheck
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// Path is the readiness path bwcdklwalambda configures for the Lambda Web
+// Adapter (AWS_LWA_READINESS_CHECK_PATH).
+const Path = "/health"
+
+const Timeout = 10 * time.Second
+
+type Result struct {
+	URL        string
+	Addrs      []string
+	Status     int
+	Latency    time.Duration
+	CertExpiry time.Time
+	Err        error
+}
+
+// OK reports whether the endpoint resolved and answered with a 2xx status.
+func (r Result) OK() bool {
+	return r.Err == nil && r.Status >= 200 && r.Status < 300
+}
+
+// Check resolves the host of rawURL and sends a GET request to it.
+func Check(ctx context.Context, client *http.Client, rawURL string) Result {
+	res := Result{URL: rawURL}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		res.Err = errors.Wrap(err, "parsing URL")
+		return res
+	}
+	addrs, err := net.DefaultResolver.LookupHost(ctx, u.Hostname())
+	if err != nil {
+		res.Err = errors.Wrap(err, "resolving host")
+		return res
+	}
+	res.Addrs = addrs
+
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		res.Err = errors.Wrap(err, "building request")
+		return res
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	res.Latency = time.Since(start)
+	res.Status = resp.StatusCode
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		res.CertExpiry = resp.TLS.PeerCertificates[0].NotAfter
+	}
+	return res
+}
+
+// HealthURL joins a gateway base URL with the readiness path.
+func HealthURL(base string) string {
+	u, err := url.Parse(base)
+	if err != nil {
+		return base + Path
+	}
+	return u.JoinPath(Path).String()
+}
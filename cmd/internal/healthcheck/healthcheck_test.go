@@ -0,0 +1,51 @@
+package healthcheck_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/basewarphq/bw/cmd/internal/healthcheck"
+)
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != healthcheck.Path {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	res := healthcheck.Check(context.Background(), srv.Client(), healthcheck.HealthURL(srv.URL))
+	if !res.OK() {
+		t.Fatalf("expected healthy result, got %+v", res)
+	}
+	if len(res.Addrs) == 0 {
+		t.Error("expected resolved addresses")
+	}
+	if res.CertExpiry.IsZero() {
+		t.Error("expected certificate expiry")
+	}
+
+	res = healthcheck.Check(context.Background(), srv.Client(), srv.URL+"/missing")
+	if res.OK() || res.Status != http.StatusNotFound {
+		t.Errorf("expected 404 result, got %+v", res)
+	}
+}
+
+func TestHealthURL(t *testing.T) {
+	t.Parallel()
+	for base, want := range map[string]string{
+		"https://api.example.com":      "https://api.example.com/health",
+		"https://api.example.com/":     "https://api.example.com/health",
+		"https://api.example.com/prod": "https://api.example.com/prod/health",
+	} {
+		if got := healthcheck.HealthURL(base); got != want {
+			t.Errorf("HealthURL(%q) = %q, want %q", base, got, want)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package parallel
+
+import "sync"
+
+// ForEach calls fn for every index in [0, n) with at most limit calls in
+// flight, and returns once all calls have finished. Callers collect results
+// by writing to their own slot of a pre-sized slice.
+func ForEach(n, limit int, fn func(i int)) {
+	if limit < 1 {
+		limit = 1
+	}
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			fn(i)
+		})
+	}
+	wg.Wait()
+}
@@ -0,0 +1,41 @@
+package parallel_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/basewarphq/bw/cmd/internal/parallel"
+)
+
+func TestForEach_VisitsAll(t *testing.T) {
+	t.Parallel()
+	results := make([]int, 20)
+	parallel.ForEach(len(results), 4, func(i int) {
+		results[i] = i * i
+	})
+	for i, got := range results {
+		if got != i*i {
+			t.Errorf("results[%d] = %d, want %d", i, got, i*i)
+		}
+	}
+}
+
+func TestForEach_RespectsLimit(t *testing.T) {
+	t.Parallel()
+	var inFlight, peak atomic.Int32
+	parallel.ForEach(16, 3, func(int) {
+		cur := inFlight.Add(1)
+		for {
+			old := peak.Load()
+			if cur <= old || peak.CompareAndSwap(old, cur) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		inFlight.Add(-1)
+	})
+	if got := peak.Load(); got > 3 {
+		t.Errorf("peak concurrency = %d, want <= 3", got)
+	}
+}
@@ -0,0 +1,179 @@
+// Package stackoutputs collects the CloudFormation outputs of a deployment
+// under stable, environment-variable style keys.
+package stackoutputs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/basewarphq/bw/cmd/internal/cdkctx"
+	"github.com/basewarphq/bw/cmd/internal/cfnread"
+	"github.com/cockroachdb/errors"
+	"github.com/iancoleman/strcase"
+	"gopkg.in/yaml.v3"
+)
+
+type Output struct {
+	Key       string `json:"key"`
+	Value     string `json:"value"`
+	Stack     string `json:"stack"`
+	Region    string `json:"region"`
+	OutputKey string `json:"output_key"`
+}
+
+// suffixKinds end output keys that bwcdk constructs prefix with an
+// identifier, e.g. "BackendCorebackApiGatewayURLGlobal".
+var suffixKinds = []string{"GatewayURLRegional", "GatewayURLGlobal", "LogGroup"}
+
+// prefixKinds start output keys that bwcdk constructs suffix with an
+// identifier, e.g. "OnePasswordSyncSecretNameDev03Backend".
+var prefixKinds = []string{"OnePasswordSyncRoleARN", "OnePasswordSyncSecretName"}
+
+// Key maps a stack output key to a stable name: the kind of output first,
+// then its identifier, with the deployment name removed so the same key works
+// for every slot. Outputs of secondary regions get the region ident appended.
+func Key(outputKey, deployment, regionIdent string, primary bool) string {
+	name := trimDeployment(outputKey, deployment)
+
+	for _, kind := range suffixKinds {
+		if ident, ok := strings.CutSuffix(name, kind); ok {
+			if ident = trimDeployment(ident, deployment); ident != "" {
+				name = kind + "_" + ident
+				break
+			}
+		}
+	}
+	for _, kind := range prefixKinds {
+		if ident, ok := strings.CutPrefix(name, kind); ok {
+			if ident = trimDeployment(ident, deployment); ident != "" {
+				name = kind + "_" + ident
+				break
+			}
+		}
+	}
+
+	key := strcase.ToScreamingSnake(name)
+	if !primary {
+		key += "_" + strings.ToUpper(regionIdent)
+	}
+	return key
+}
+
+// trimDeployment removes the deployment name when it is the first or last
+// word of the camel-case name. Elsewhere it is part of another word, like
+// "Prod" in "ProductTable", and stays.
+func trimDeployment(name, deployment string) string {
+	if deployment == "" {
+		return name
+	}
+	if rest, ok := strings.CutPrefix(name, deployment); ok && rest != "" {
+		if r, _ := utf8.DecodeRuneInString(rest); unicode.IsUpper(r) || unicode.IsDigit(r) {
+			return rest
+		}
+	}
+	if rest, ok := strings.CutSuffix(name, deployment); ok && rest != "" {
+		return rest
+	}
+	return name
+}
+
+// Collect reads the outputs of the shared and deployment stacks in every
+// region. Stacks that are not deployed are skipped.
+func Collect(ctx context.Context, cctx *cdkctx.CDKContext, profile, deployment string) ([]Output, error) {
+	type stack struct {
+		ref        cdkctx.StackRef
+		deployment string
+	}
+	var stacks []stack
+	for _, ref := range cctx.SharedStacks() {
+		stacks = append(stacks, stack{ref: ref})
+	}
+	for _, ref := range cctx.DeploymentStacks(deployment) {
+		stacks = append(stacks, stack{ref: ref, deployment: deployment})
+	}
+
+	var outputs []Output
+	seen := map[string]Output{}
+	for _, s := range stacks {
+		values, err := cfnread.StackOutputs(ctx, s.ref.Region, profile, s.ref.Name)
+		if errors.Is(err, cfnread.ErrStackNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		keys := make([]string, 0, len(values))
+		for k := range values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			o := Output{
+				Key:       Key(k, s.deployment, cctx.RegionIdent(s.ref.Region), s.ref.Region == cctx.PrimaryRegion),
+				Value:     values[k],
+				Stack:     s.ref.Name,
+				Region:    s.ref.Region,
+				OutputKey: k,
+			}
+			if prev, ok := seen[o.Key]; ok {
+				return nil, errors.Newf("outputs %s of %s and %s of %s both map to %s",
+					prev.OutputKey, prev.Stack, o.OutputKey, o.Stack, o.Key)
+			}
+			seen[o.Key] = o
+			outputs = append(outputs, o)
+		}
+	}
+	sort.Slice(outputs, func(i, j int) bool { return outputs[i].Key < outputs[j].Key })
+	return outputs, nil
+}
+
+const (
+	FormatEnv  = "env"
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// Write renders outputs as a dotenv file, or as a JSON or YAML object of
+// key to value.
+func Write(w io.Writer, outputs []Output, format string) error {
+	switch format {
+	case FormatEnv:
+		for _, o := range outputs {
+			if _, err := fmt.Fprintf(w, "%s=%s\n", o.Key, envValue(o.Value)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(valueMap(outputs))
+	case FormatYAML:
+		return yaml.NewEncoder(w).Encode(valueMap(outputs))
+	default:
+		return errors.Newf("unknown output format %q", format)
+	}
+}
+
+func valueMap(outputs []Output) map[string]string {
+	m := make(map[string]string, len(outputs))
+	for _, o := range outputs {
+		m[o.Key] = o.Value
+	}
+	return m
+}
+
+func envValue(v string) string {
+	if v == "" || strings.ContainsAny(v, " \t\n\"'#$\\`") {
+		return strconv.Quote(v)
+	}
+	return v
+}
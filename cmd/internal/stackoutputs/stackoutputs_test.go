@@ -0,0 +1,64 @@
+package stackoutputs_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/basewarphq/bw/cmd/internal/stackoutputs"
+)
+
+func TestKey(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		outputKey, deployment, regionIdent string
+		primary                            bool
+		want                               string
+	}{
+		{"ApiGatewayURLGlobal", "Dev03", "Euc1", true, "GATEWAY_URL_GLOBAL_API"},
+		{"BackendCorebackApiGatewayURLRegional", "Dev03", "Euc1", true, "GATEWAY_URL_REGIONAL_BACKEND_COREBACK_API"},
+		{"ApiGatewayURLRegional", "Dev03", "Use1", false, "GATEWAY_URL_REGIONAL_API_USE1"},
+		{"BackendLogGroup", "Dev03", "Euc1", true, "LOG_GROUP_BACKEND"},
+		{"OnePasswordSyncSecretNameDev03Backend", "Dev03", "Euc1", true, "ONE_PASSWORD_SYNC_SECRET_NAME_BACKEND"},
+		{"OnePasswordSAMLProviderARN", "", "Euc1", true, "ONE_PASSWORD_SAML_PROVIDER_ARN"},
+		{"HostedZoneNameServers", "", "Euc1", true, "HOSTED_ZONE_NAME_SERVERS"},
+		{"LogGroup", "Dev03", "Euc1", true, "LOG_GROUP"},
+		{"ProductTableName", "Prod", "Euc1", true, "PRODUCT_TABLE_NAME"},
+		{"ProductsLogGroup", "Prod", "Euc1", true, "LOG_GROUP_PRODUCTS"},
+		{"ProdApiGatewayURLGlobal", "Prod", "Euc1", true, "GATEWAY_URL_GLOBAL_API"},
+		{"ApiUrlProd", "Prod", "Euc1", true, "API_URL"},
+	}
+	for _, tt := range tests {
+		got := stackoutputs.Key(tt.outputKey, tt.deployment, tt.regionIdent, tt.primary)
+		if got != tt.want {
+			t.Errorf("Key(%q) = %q, want %q", tt.outputKey, got, tt.want)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+	outputs := []stackoutputs.Output{
+		{Key: "GATEWAY_URL_GLOBAL_API", Value: "https://api.example.com"},
+		{Key: "NOTE", Value: "has spaces"},
+	}
+
+	tests := map[string]string{
+		stackoutputs.FormatEnv: "GATEWAY_URL_GLOBAL_API=https://api.example.com\nNOTE=\"has spaces\"\n",
+		stackoutputs.FormatJSON: "{\n  \"GATEWAY_URL_GLOBAL_API\": \"https://api.example.com\",\n" +
+			"  \"NOTE\": \"has spaces\"\n}\n",
+		stackoutputs.FormatYAML: "GATEWAY_URL_GLOBAL_API: https://api.example.com\nNOTE: has spaces\n",
+	}
+	for format, want := range tests {
+		var buf bytes.Buffer
+		if err := stackoutputs.Write(&buf, outputs, format); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != want {
+			t.Errorf("%s: got\n%s\nwant\n%s", format, buf.String(), want)
+		}
+	}
+
+	if err := stackoutputs.Write(&bytes.Buffer{}, outputs, "xml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
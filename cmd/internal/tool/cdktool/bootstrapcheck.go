@@ -0,0 +1,244 @@
+package cdktool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/basewarphq/bw/cmd/internal/cdkctx"
+	"github.com/basewarphq/bw/cmd/internal/cfnpatch"
+	"github.com/basewarphq/bw/cmd/internal/cfnread"
+	"github.com/basewarphq/bw/cmd/internal/cmdexec"
+	"github.com/basewarphq/bw/cmd/internal/parallel"
+	"github.com/basewarphq/bw/cmd/internal/tool"
+	"github.com/cockroachdb/errors"
+)
+
+const defaultToolkitStackName = "CDKToolkit"
+
+// bootstrapStatus is what is deployed as the bootstrap stack in one region.
+type bootstrapStatus struct {
+	Region    string
+	StackName string
+	Version   int
+	// Expected is the version the local cdk CLI bootstraps with.
+	Expected    int
+	DevSlotRule bool
+	Err         error
+}
+
+func (s bootstrapStatus) problems() []string {
+	switch {
+	case errors.Is(s.Err, cfnread.ErrStackNotFound):
+		return []string{s.Region + ": not bootstrapped; run 'bw infra bootstrap'"}
+	case s.Err != nil:
+		return []string{s.Region + ": " + cmdexec.Summary(s.Err)}
+	}
+	var problems []string
+	if s.Version < s.Expected {
+		problems = append(problems, fmt.Sprintf(
+			"%s: bootstrap version %d is older than %d expected by the local cdk; run 'bw infra bootstrap'",
+			s.Region, s.Version, s.Expected))
+	}
+	if !s.DevSlotRule {
+		problems = append(problems, fmt.Sprintf(
+			"%s: staging bucket has no %s lifecycle rule scoped to lock objects; run 'bw infra bootstrap'",
+			s.Region, cfnpatch.DevSlotRuleID))
+	}
+	return problems
+}
+
+// toolkitStackNames returns the bootstrap stack names to look for, the
+// configured one first.
+func toolkitStackNames(cfg *cdkConfig, qualifier string) []string {
+	legacy := qualifier + "Bootstrap"
+	if cfg.LegacyBootstrap {
+		return []string{legacy, defaultToolkitStackName}
+	}
+	return []string{defaultToolkitStackName, legacy}
+}
+
+func checkBootstrap(ctx context.Context, cfg *cdkConfig, dir string) ([]bootstrapStatus, error) {
+	cctx, err := cdkctx.Load(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	templateYAML, err := cmdexec.Output(ctx, dir, "cdk", "bootstrap", "--show-template")
+	if err != nil {
+		return nil, errors.Wrap(err, "getting default bootstrap template")
+	}
+	expected, err := cfnpatch.BootstrapVersion([]byte(templateYAML))
+	if err != nil {
+		return nil, errors.Wrap(err, "reading bootstrap version of the local cdk")
+	}
+
+	regions := cctx.AllRegions()
+	statuses := make([]bootstrapStatus, len(regions))
+	parallel.ForEach(len(regions), len(regions), func(i int) {
+		statuses[i] = regionBootstrapStatus(ctx, cfg, cctx.Qualifier, regions[i])
+		statuses[i].Expected = expected
+	})
+	return statuses, nil
+}
+
+func regionBootstrapStatus(ctx context.Context, cfg *cdkConfig, qualifier, region string) bootstrapStatus {
+	st := bootstrapStatus{Region: region}
+
+	var stack *cfnread.Stack
+	for _, name := range toolkitStackNames(cfg, qualifier) {
+		stack, st.Err = cfnread.DescribeStack(ctx, region, cfg.Profile, name)
+		if !errors.Is(st.Err, cfnread.ErrStackNotFound) {
+			break
+		}
+	}
+	if st.Err != nil {
+		return st
+	}
+	st.StackName = stack.Name
+
+	if st.Version, st.Err = strconv.Atoi(stack.Outputs["BootstrapVersion"]); st.Err != nil {
+		st.Err = errors.Newf("%s has no numeric BootstrapVersion output", stack.Name)
+		return st
+	}
+
+	rules, err := bucketLifecycleRules(ctx, region, cfg.Profile, stack.Outputs["BucketName"])
+	if err != nil {
+		st.Err = err
+		return st
+	}
+	for _, rule := range rules {
+		// A rule from before locks were tagged also expires the slot history.
+		if rule.ID == cfnpatch.DevSlotRuleID && rule.hasTag(cfnpatch.DevSlotLockTagKey, cfnpatch.DevSlotLockTagValue) {
+			st.DevSlotRule = true
+		}
+	}
+	return st
+}
+
+type lifecycleTag struct {
+	Key   string `json:"Key"`
+	Value string `json:"Value"`
+}
+
+type lifecycleRule struct {
+	ID     string `json:"ID"`
+	Filter struct {
+		Tag *lifecycleTag `json:"Tag"`
+		And struct {
+			Tags []lifecycleTag `json:"Tags"`
+		} `json:"And"`
+	} `json:"Filter"`
+}
+
+func (r lifecycleRule) hasTag(key, value string) bool {
+	want := lifecycleTag{Key: key, Value: value}
+	return r.Filter.Tag != nil && *r.Filter.Tag == want || slices.Contains(r.Filter.And.Tags, want)
+}
+
+func bucketLifecycleRules(ctx context.Context, region, profile, bucket string) ([]lifecycleRule, error) {
+	args := []string{
+		"s3api", "get-bucket-lifecycle-configuration",
+		"--no-cli-pager",
+		"--region", region,
+		"--bucket", bucket,
+		"--output", "json",
+	}
+	if profile != "" {
+		args = append(args, "--profile", profile)
+	}
+	out, err := cmdexec.Output(ctx, "/", "aws", args...)
+	if err != nil {
+		var cmdErr *cmdexec.Error
+		if errors.As(err, &cmdErr) && strings.Contains(cmdErr.Stderr, "NoSuchLifecycleConfiguration") {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "reading lifecycle rules of %s", bucket)
+	}
+
+	var resp struct {
+		Rules []lifecycleRule `json:"Rules"`
+	}
+	if err := json.Unmarshal([]byte(out), &resp); err != nil {
+		return nil, errors.Wrapf(err, "parsing lifecycle rules of %s", bucket)
+	}
+	return resp.Rules, nil
+}
+
+func reportBootstrap(statuses []bootstrapStatus, r tool.NodeReporter) []string {
+	rows := make([][]string, 0, len(statuses))
+	var problems []string
+	for _, st := range statuses {
+		version, rule := "-", "-"
+		if st.Err == nil {
+			version = fmt.Sprintf("%d (expected %d)", st.Version, st.Expected)
+			rule = yesNo(st.DevSlotRule)
+		}
+		rows = append(rows, []string{st.Region, st.StackName, version, rule})
+		problems = append(problems, st.problems()...)
+	}
+	r.Table([]string{"Region", "Stack", "Version", "Dev slot rule"}, rows)
+	for _, p := range problems {
+		r.Error(p)
+	}
+	return problems
+}
+
+// diagnoseBootstrap is the bootstrap check of doctor. It needs AWS, so when
+// AWS cannot be reached, for lack of credentials or network, the check is
+// reported as skipped and the local checks still pass. Problems with a
+// bootstrap stack that could be read fail doctor.
+func diagnoseBootstrap(ctx context.Context, cfg *cdkConfig, dir string, r tool.NodeReporter) error {
+	statuses, err := checkBootstrap(ctx, cfg, dir)
+	if err != nil {
+		r.Table(nil, [][]string{{"⚠", "bootstrap: skipped, " + cmdexec.Summary(err)}})
+		return nil
+	}
+
+	reachable, skipped := splitUnreachable(statuses)
+	if len(skipped) > 0 {
+		rows := make([][]string, len(skipped))
+		for i, s := range skipped {
+			rows[i] = []string{"⚠", "bootstrap: skipped " + s}
+		}
+		r.Table(nil, rows)
+	}
+	if len(reachable) == 0 {
+		return nil
+	}
+	if problems := reportBootstrap(reachable, r); len(problems) > 0 {
+		return errors.Newf("doctor checks failed: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// splitUnreachable separates the regions whose bootstrap stack could not be
+// read from those that were checked. A missing stack counts as checked.
+func splitUnreachable(statuses []bootstrapStatus) (reachable []bootstrapStatus, skipped []string) {
+	for _, st := range statuses {
+		if st.Err != nil && !errors.Is(st.Err, cfnread.ErrStackNotFound) {
+			skipped = append(skipped, st.Region+", "+cmdexec.Summary(st.Err))
+			continue
+		}
+		reachable = append(reachable, st)
+	}
+	return reachable, skipped
+}
+
+func inspectBootstrap(ctx context.Context, dir string, r tool.NodeReporter) error {
+	cfg := configFromCtx(ctx)
+	opts, _ := tool.InspectOptionsFrom(ctx)
+
+	statuses, err := checkBootstrap(ctx, cfg, cfg.resolveDir(dir))
+	if err != nil {
+		return err
+	}
+	problems := reportBootstrap(statuses, r)
+	if opts.Strict && len(problems) > 0 {
+		return errors.Newf("bootstrap stack needs attention in %d place(s)", len(problems))
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package cdktool
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/basewarphq/bw/cmd/internal/cfnpatch"
+	"github.com/basewarphq/bw/cmd/internal/cfnread"
+	"github.com/cockroachdb/errors"
+)
+
+func TestBootstrapStatusProblems(t *testing.T) {
+	t.Parallel()
+
+	current := bootstrapStatus{Region: "eu-central-1", Version: 30, Expected: 30, DevSlotRule: true}
+	if p := current.problems(); len(p) != 0 {
+		t.Errorf("expected no problems, got %v", p)
+	}
+
+	newer := bootstrapStatus{Region: "eu-central-1", Version: 31, Expected: 30, DevSlotRule: true}
+	if p := newer.problems(); len(p) != 0 {
+		t.Errorf("a newer bootstrap than the local cdk should pass, got %v", p)
+	}
+
+	stale := bootstrapStatus{Region: "eu-west-1", Version: 21, Expected: 30}
+	p := stale.problems()
+	if len(p) != 2 || !strings.Contains(p[0], "21") || !strings.Contains(p[1], "CleanupDevSlotClaims") {
+		t.Errorf("expected version and lifecycle problems, got %v", p)
+	}
+
+	missing := bootstrapStatus{Region: "eu-west-1", Err: errors.Mark(errors.New("gone"), cfnread.ErrStackNotFound)}
+	if p := missing.problems(); len(p) != 1 || !strings.Contains(p[0], "not bootstrapped") {
+		t.Errorf("expected not bootstrapped, got %v", p)
+	}
+}
+
+func TestToolkitStackNames(t *testing.T) {
+	t.Parallel()
+
+	if got := toolkitStackNames(&cdkConfig{}, "bwapp"); got[0] != "CDKToolkit" || got[1] != "bwappBootstrap" {
+		t.Errorf("got %v", got)
+	}
+	if got := toolkitStackNames(&cdkConfig{LegacyBootstrap: true}, "bwapp"); got[0] != "bwappBootstrap" {
+		t.Errorf("legacy name should be tried first, got %v", got)
+	}
+}
+
+func TestSplitUnreachable(t *testing.T) {
+	t.Parallel()
+
+	statuses := []bootstrapStatus{
+		{Region: "eu-central-1", Version: 30, Expected: 30, DevSlotRule: true},
+		{Region: "eu-west-1", Err: errors.Mark(errors.New("gone"), cfnread.ErrStackNotFound)},
+		{Region: "us-east-1", Err: errors.New("no credentials")},
+	}
+	reachable, skipped := splitUnreachable(statuses)
+	if len(reachable) != 2 || reachable[0].Region != "eu-central-1" || reachable[1].Region != "eu-west-1" {
+		t.Errorf("reachable: got %+v", reachable)
+	}
+	if len(skipped) != 1 || !strings.HasPrefix(skipped[0], "us-east-1") {
+		t.Errorf("skipped: got %v", skipped)
+	}
+}
+
+func TestLifecycleRuleHasTag(t *testing.T) {
+	t.Parallel()
+
+	var resp struct {
+		Rules []lifecycleRule `json:"Rules"`
+	}
+	err := json.Unmarshal([]byte(`{"Rules": [
+		{"ID": "CleanupDevSlotClaims", "Filter": {"Prefix": "dev-slots/"}},
+		{"ID": "CleanupDevSlotClaims", "Filter": {"And": {"Prefix": "dev-slots/",
+			"Tags": [{"Key": "bw-dev-slot", "Value": "lock"}]}}},
+		{"ID": "CleanupDevSlotClaims", "Filter": {"Tag": {"Key": "bw-dev-slot", "Value": "lock"}}}
+	]}`), &resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []bool{false, true, true} {
+		if got := resp.Rules[i].hasTag(cfnpatch.DevSlotLockTagKey, cfnpatch.DevSlotLockTagValue); got != want {
+			t.Errorf("rule %d: got %v, want %v", i, got, want)
+		}
+	}
+}
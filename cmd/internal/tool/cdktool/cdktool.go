@@ -9,6 +9,8 @@ import (
 
 	"github.com/BurntSushi/toml"
 	"github.com/basewarphq/bw/cmd/internal/cdkctx"
+	"github.com/basewarphq/bw/cmd/internal/cdkdiff"
+	"github.com/basewarphq/bw/cmd/internal/cdkmanifest"
 	"github.com/basewarphq/bw/cmd/internal/cfndeploy"
 	"github.com/basewarphq/bw/cmd/internal/cfnparams"
 	"github.com/basewarphq/bw/cmd/internal/cfnpatch"
@@ -17,18 +19,43 @@ import (
 	"github.com/basewarphq/bw/cmd/internal/cmdexec"
 	"github.com/basewarphq/bw/cmd/internal/devslot"
 	"github.com/basewarphq/bw/cmd/internal/devstrategy"
+	"github.com/basewarphq/bw/cmd/internal/gitinfo"
+	"github.com/basewarphq/bw/cmd/internal/parallel"
 	"github.com/basewarphq/bw/cmd/internal/tool"
 	"github.com/cockroachdb/errors"
 )
 
-const devSlotExpirationDays = 7
+// changeSetPrefix prefixes the git revision in the change set name of every
+// deploy, so the deployed commit can be read back from the stack's last
+// change set without adding tags or outputs to the templates.
+const changeSetPrefix = "bw-"
+
+const (
+	devStrategyIAMUsername = "iam-username"
+	devStrategyBranch      = "branch"
+)
 
 type cdkConfig struct {
-	Dir             string              `toml:"dir"`
-	Profile         string              `toml:"profile"`
-	DevStrategy     string              `toml:"dev-strategy"`
-	LegacyBootstrap bool                `toml:"legacy-bootstrap"`
-	PreBootstrap    *preBootstrapConfig `toml:"pre-bootstrap"`
+	Dir             string                  `toml:"dir"`
+	Profile         string                  `toml:"profile"`
+	DevStrategy     string                  `toml:"dev-strategy"`
+	LegacyBootstrap bool                    `toml:"legacy-bootstrap"`
+	PreBootstrap    *preBootstrapConfig     `toml:"pre-bootstrap"`
+	Slots           slotsConfig             `toml:"slots"`
+	Policies        map[string]deployPolicy `toml:"policy"`
+	// AccountProfiles maps AWS account IDs to the profile to use for the
+	// deployments in that account, for apps that map deployments to accounts
+	// with {prefix}deployment-accounts. Profile applies to other accounts.
+	AccountProfiles map[string]string `toml:"account-profiles"`
+	// BootstrapPatches are applied in order to the default bootstrap
+	// template before it is deployed.
+	BootstrapPatches []patchConfig `toml:"bootstrap-patches"`
+}
+
+type slotsConfig struct {
+	History     string            `toml:"history"`
+	Pools       map[string]string `toml:"pools"`
+	DefaultPool string            `toml:"default-pool"`
 }
 
 type preBootstrapConfig struct {
@@ -43,6 +70,29 @@ func (c *cdkConfig) resolveDir(projectDir string) string {
 	return projectDir
 }
 
+// forDeployment returns the config for commands on the stacks of deployment:
+// Profile becomes the profile of the deployment's account when
+// account-profiles names one.
+func (c *cdkConfig) forDeployment(cctx *cdkctx.CDKContext, deployment string) *cdkConfig {
+	profile, ok := c.AccountProfiles[cctx.DeploymentAccount(deployment)]
+	if !ok {
+		return c
+	}
+	cfg := *c
+	cfg.Profile = profile
+	return &cfg
+}
+
+func (c *cdkConfig) slotSettings() devslot.Settings {
+	return devslot.Settings{
+		Profile:         c.Profile,
+		AccountProfiles: c.AccountProfiles,
+		History:         c.Slots.History,
+		Pools:           c.Slots.Pools,
+		Pool:            c.Slots.DefaultPool,
+	}
+}
+
 func (c *cdkConfig) cdkArgs(qualifier string) []string {
 	var args []string
 	if c.LegacyBootstrap {
@@ -72,8 +122,11 @@ func (t *Tool) DecodeConfig(meta toml.MetaData, raw toml.Primitive) (any, error)
 	if cfg.Dir != "" && filepath.IsAbs(cfg.Dir) {
 		return nil, errors.Newf("dir must be relative, got %q", cfg.Dir)
 	}
-	if cfg.DevStrategy != "" && cfg.DevStrategy != "iam-username" {
-		return nil, errors.Newf("dev-strategy must be %q, got %q", "iam-username", cfg.DevStrategy)
+	switch cfg.DevStrategy {
+	case "", devStrategyIAMUsername, devStrategyBranch:
+	default:
+		return nil, errors.Newf("dev-strategy must be %q or %q, got %q",
+			devStrategyIAMUsername, devStrategyBranch, cfg.DevStrategy)
 	}
 	if pb := cfg.PreBootstrap; pb != nil {
 		if pb.Template == "" {
@@ -83,6 +136,23 @@ func (t *Tool) DecodeConfig(meta toml.MetaData, raw toml.Primitive) (any, error)
 			return nil, errors.Newf("pre-bootstrap.template must be relative, got %q", pb.Template)
 		}
 	}
+	if h := cfg.Slots.History; h != "" && h != "none" && !strings.HasPrefix(h, "s3://") {
+		return nil, errors.Newf("slots.history must be %q or an s3:// URL, got %q", "none", h)
+	}
+	if err := devslot.ValidatePools(cfg.Slots.Pools, cfg.Slots.DefaultPool); err != nil {
+		return nil, errors.Wrap(err, "slots")
+	}
+	for account, profile := range cfg.AccountProfiles {
+		if profile == "" {
+			return nil, errors.Newf("account-profiles: empty profile for account %s", account)
+		}
+	}
+	if err := validatePolicies(cfg.Policies); err != nil {
+		return nil, err
+	}
+	if _, err := cfg.bootstrapPatches(); err != nil {
+		return nil, err
+	}
 	return cfg, nil
 }
 
@@ -102,7 +172,15 @@ func (t *Tool) RequiredFiles() []tool.FileRequirement {
 
 func (t *Tool) Diagnose(ctx context.Context, dir string, r tool.NodeReporter) error {
 	cfg := configFromCtx(ctx)
-	return tool.DiagnoseDefaults(ctx, cfg.resolveDir(dir), t, tool.BinCheckerFrom(ctx), r)
+	dir = cfg.resolveDir(dir)
+	if err := tool.DiagnoseDefaults(ctx, dir, t, tool.BinCheckerFrom(ctx), r); err != nil {
+		return err
+	}
+	if err := checkContext(dir, r); err != nil {
+		return errors.Wrap(err, "doctor checks failed")
+	}
+
+	return diagnoseBootstrap(ctx, cfg, dir, r)
 }
 
 func (t *Tool) Bootstrap(ctx context.Context, dir string, _ tool.NodeReporter) error {
@@ -110,6 +188,15 @@ func (t *Tool) Bootstrap(ctx context.Context, dir string, _ tool.NodeReporter) e
 	dir = cfg.resolveDir(dir)
 	opts, _ := tool.BootstrapOptionsFrom(ctx)
 
+	if opts.ShowTemplate {
+		template, err := patchedBootstrapTemplate(ctx, cfg, dir)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(template)
+		return err
+	}
+
 	profile := opts.Profile
 	if profile == "" {
 		profile = cfg.Profile
@@ -127,7 +214,7 @@ func (t *Tool) Bootstrap(ctx context.Context, dir string, _ tool.NodeReporter) e
 		return err
 	}
 
-	templatePath, err := patchedBootstrapTemplate(ctx, dir)
+	templatePath, err := writeBootstrapTemplate(ctx, cfg, dir)
 	if err != nil {
 		return err
 	}
@@ -155,9 +242,10 @@ func (t *Tool) Bootstrap(ctx context.Context, dir string, _ tool.NodeReporter) e
 	return cmdexec.Run(ctx, dir, "cdk", args...)
 }
 
-func (t *Tool) Diff(ctx context.Context, dir string, _ tool.NodeReporter) error {
+func (t *Tool) Diff(ctx context.Context, dir string, r tool.NodeReporter) error {
 	cfg := configFromCtx(ctx)
 	dir = cfg.resolveDir(dir)
+	opts, _ := tool.DiffOptionsFrom(ctx)
 
 	deployment, err := resolveDeployment(ctx, cfg, dir)
 	if err != nil {
@@ -168,13 +256,25 @@ func (t *Tool) Diff(ctx context.Context, dir string, _ tool.NodeReporter) error
 	if err != nil {
 		return err
 	}
+	cfg = cfg.forDeployment(cctx, deployment)
 
-	cdkArgs := cfg.cdkArgs(cctx.Qualifier)
-	args := make([]string, 0, 3+len(cdkArgs))
-	args = append(args, "diff")
-	args = append(args, cdkArgs...)
-	args = append(args, cctx.Qualifier+"*Shared", cctx.Qualifier+"*"+deployment)
-	return cmdexec.Run(ctx, dir, "cdk", args...)
+	stacks, err := deploymentStacks(ctx, cfg, cctx, dir, deployment)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"diff"}
+	args = append(args, cfg.cdkArgs(cctx.Qualifier)...)
+	args = append(args, stacks.cdkArgs(stacks.All())...)
+	if opts.Raw {
+		return cmdexec.Run(ctx, dir, "cdk", args...)
+	}
+
+	out, err := cmdexec.CombinedOutput(ctx, dir, "cdk", append(args, "--no-color")...)
+	if err != nil {
+		return err
+	}
+	return reportDiff(cdkdiff.Parse(out), opts, r)
 }
 
 func (t *Tool) Deploy(ctx context.Context, dir string, _ tool.NodeReporter) error {
@@ -182,6 +282,10 @@ func (t *Tool) Deploy(ctx context.Context, dir string, _ tool.NodeReporter) erro
 	dir = cfg.resolveDir(dir)
 	opts, _ := tool.DeployOptionsFrom(ctx)
 
+	if opts.ApplyFile != "" {
+		return t.applyPlanFile(ctx, dir, cfg, opts)
+	}
+
 	deployment, err := resolveDeployment(ctx, cfg, dir)
 	if err != nil {
 		return err
@@ -191,14 +295,39 @@ func (t *Tool) Deploy(ctx context.Context, dir string, _ tool.NodeReporter) erro
 	if err != nil {
 		return err
 	}
+	cfg = cfg.forDeployment(cctx, deployment)
 
-	args := []string{"deploy", "--require-approval", "never"}
+	if err := cfg.checkPolicies(ctx, dir, deployment, opts); err != nil {
+		return err
+	}
+	stacks, err := deploymentStacks(ctx, cfg, cctx, dir, deployment)
+	if err != nil {
+		return err
+	}
+	if opts.PlanFile != "" {
+		return t.plan(ctx, dir, cfg, cctx, stacks, deployment, opts)
+	}
+
+	approval := cfg.approvalFor(deployment)
+	args := []string{"deploy", "--require-approval", approval}
 	if opts.Hotswap {
 		args = append(args, "--hotswap")
+	} else if rev := gitinfo.Revision(ctx, dir); rev != "" {
+		args = append(args, "--change-set-name", changeSetPrefix+rev)
 	}
 	args = append(args, cfg.cdkArgs(cctx.Qualifier)...)
-	args = append(args, cctx.Qualifier+"*Shared", cctx.Qualifier+"*"+deployment)
-	return cmdexec.Run(ctx, dir, "cdk", args...)
+	args = append(args, stacks.cdkArgs(stacks.All())...)
+
+	var deployErr error
+	// Hotswap bypasses CloudFormation and approval prompts need cdk's output,
+	// so both stream cdk as-is.
+	if opts.Raw || opts.Hotswap || approval != approvalNever || !stderrIsTerminal() {
+		deployErr = cmdexec.Run(ctx, dir, "cdk", args...)
+	} else {
+		deployErr = deployWithProgress(ctx, dir, cfg, stacks.All(), args)
+	}
+	devslot.RecordDeploy(ctx, dir, cfg.slotSettings(), deployment, deployErr)
+	return deployErr
 }
 
 func (t *Tool) Inspections() []tool.Inspection {
@@ -206,40 +335,21 @@ func (t *Tool) Inspections() []tool.Inspection {
 		{Name: "endpoints", Description: "API Gateway endpoint URLs", Run: inspectOutputsByKey("GatewayURL")},
 		{Name: "logs", Description: "CloudWatch log group names", Run: inspectOutputsByKey("LogGroup")},
 		{Name: "1password-sync", Description: "1Password sync configuration", Run: inspect1PasswordSync},
+		{Name: "drift", Description: "Resources changed outside CloudFormation", Run: inspectDrift, OptIn: true},
+		{Name: "health", Description: "Call the health path of every gateway URL", Run: inspectHealth, OptIn: true},
+		{Name: "dns", Description: "Delegation of the base domain to the hosted zone", Run: inspectDNS, OptIn: true},
+		{Name: "bootstrap", Description: "Bootstrap stack version and dev slot lifecycle rule", Run: inspectBootstrap, OptIn: true},
 	}
 }
 
-type stackInfo struct {
-	Name   string
-	Region string
-}
-
-func listDeploymentStacks(
-	ctx context.Context, dir string, cfg *cdkConfig,
-	cctx *cdkctx.CDKContext, deployment string,
-) ([]stackInfo, error) {
-	listArgs := append([]string{"list"}, cfg.cdkArgs(cctx.Qualifier)...)
-	out, err := cmdexec.Output(ctx, dir, "cdk", listArgs...)
-	if err != nil {
-		return nil, err
-	}
-
-	var stacks []stackInfo
-	for line := range strings.SplitSeq(out, "\n") {
-		name := strings.TrimSpace(line)
-		if name == "" {
-			continue
-		}
-		if !strings.HasSuffix(name, deployment) && !strings.HasSuffix(name, "Shared") {
-			continue
-		}
-		region, ok := cctx.ResolveStackRegion(name)
-		if !ok {
-			continue
-		}
-		stacks = append(stacks, stackInfo{Name: name, Region: region})
-	}
-	return stacks, nil
+// prefetchStacks lists the qualifier's stacks once per region. Inside an
+// inspect run the listing is cached, so the lenses that follow read stacks
+// without describing each one. Failures are left for those reads to report.
+func prefetchStacks(ctx context.Context, cfg *cdkConfig, cctx *cdkctx.CDKContext) {
+	regions := cctx.AllRegions()
+	parallel.ForEach(len(regions), len(regions), func(i int) {
+		_, _ = cfnread.DescribeStacks(ctx, regions[i], cfg.Profile, cctx.Qualifier)
+	})
 }
 
 func inspectOutputsByKey(outputKeySubstr string) func(context.Context, string, tool.NodeReporter) error {
@@ -256,14 +366,16 @@ func inspectOutputsByKey(outputKeySubstr string) func(context.Context, string, t
 		if err != nil {
 			return err
 		}
+		cfg = cfg.forDeployment(cctx, deployment)
 
-		stacks, err := listDeploymentStacks(ctx, dir, cfg, cctx, deployment)
+		stacks, err := deploymentStacks(ctx, cfg, cctx, dir, deployment)
 		if err != nil {
 			return err
 		}
+		prefetchStacks(ctx, cfg, cctx)
 
-		for _, stack := range stacks {
-			if !strings.HasSuffix(stack.Name, deployment) {
+		for _, stack := range stacks.Deployment {
+			if !declaresOutput(stack, outputKeySubstr) {
 				continue
 			}
 			outputs, err := cfnread.StackOutputs(ctx, stack.Region, cfg.Profile, stack.Name)
@@ -301,27 +413,21 @@ func inspect1PasswordSync(ctx context.Context, dir string, r tool.NodeReporter)
 		return err
 	}
 
-	stacks, err := listDeploymentStacks(ctx, dir, cfg, cctx, deployment)
+	if !cctx.IsValidDeployment(deployment) {
+		return errors.Newf("unknown deployment %q", deployment)
+	}
+	cfg = cfg.forDeployment(cctx, deployment)
+	stacks, err := deploymentStacks(ctx, cfg, cctx, dir, deployment)
 	if err != nil {
 		return err
 	}
-
-	var sharedStack, deploymentStack stackInfo
-	for _, si := range stacks {
-		if sharedStack.Name == "" && strings.HasSuffix(si.Name, "Shared") {
-			sharedStack = si
-		}
-		if deploymentStack.Name == "" && strings.HasSuffix(si.Name, deployment) {
-			deploymentStack = si
-		}
-	}
-
-	if sharedStack.Name == "" {
+	if len(stacks.Shared) == 0 {
 		return errors.New("no shared stack found")
 	}
-	if deploymentStack.Name == "" {
-		return errors.Newf("no deployment stack found for %s", deployment)
-	}
+	prefetchStacks(ctx, cfg, cctx)
+
+	sharedStack := stacks.Shared[0]
+	deploymentStack := stacks.Deployment[0]
 
 	sharedOutputs, err := cfnread.StackOutputs(ctx, sharedStack.Region, cfg.Profile, sharedStack.Name)
 	if err != nil {
@@ -347,6 +453,17 @@ func inspect1PasswordSync(ctx context.Context, dir string, r tool.NodeReporter)
 	return nil
 }
 
+// declaresOutput reports whether the synthesized template of stack declares
+// an output whose key contains substr.
+func declaresOutput(stack *cdkmanifest.Stack, substr string) bool {
+	for _, key := range stack.Outputs {
+		if strings.Contains(key, substr) {
+			return true
+		}
+	}
+	return false
+}
+
 func outputContaining(outputs map[string]string, substr string) string {
 	for k, v := range outputs {
 		if strings.Contains(k, substr) {
@@ -363,11 +480,19 @@ func valueOrMissing(val, hint string) string {
 	return val
 }
 
-func ProfileFromConfig(cfg any) string {
+func DeployedCommit(stack *cfnread.Stack) string {
+	name, ok := strings.CutPrefix(stack.ChangeSetName(), changeSetPrefix)
+	if !ok {
+		return ""
+	}
+	return name
+}
+
+func SlotSettingsFromConfig(cfg any) devslot.Settings {
 	if c, ok := cfg.(cdkConfig); ok {
-		return c.Profile
+		return c.slotSettings()
 	}
-	return ""
+	return devslot.Settings{}
 }
 
 func configFromCtx(ctx context.Context) *cdkConfig {
@@ -382,10 +507,29 @@ func resolveDeployment(ctx context.Context, cfg *cdkConfig, dir string) (string,
 	if d, ok := tool.DeploymentFrom(ctx); ok && d != "" {
 		return d, nil
 	}
-	if cfg.DevStrategy == "iam-username" {
+	switch cfg.DevStrategy {
+	case devStrategyIAMUsername:
 		return devstrategy.IAMDeployment(ctx, cfg.Profile)
+	case devStrategyBranch:
+		cctx, err := cdkctx.Load(dir)
+		if err != nil {
+			return "", err
+		}
+		settings := cfg.slotSettings()
+		slots, err := settings.PoolSlots(cctx)
+		if err != nil {
+			return "", err
+		}
+		slot, branch, err := devstrategy.BranchDeployment(ctx, dir, slots)
+		if err != nil {
+			return "", err
+		}
+		if err := devslot.ClaimBranchSlot(ctx, dir, settings, slot, branch); err != nil {
+			return "", err
+		}
+		return slot, nil
 	}
-	claim, err := devslot.EnsureClaim(ctx, dir, cfg.Profile)
+	claim, err := devslot.EnsureClaim(ctx, dir, cfg.slotSettings(), devslot.ClaimOptions{})
 	if err != nil {
 		return "", err
 	}
@@ -433,15 +577,15 @@ func runPreBootstrap(
 	pb := cfg.PreBootstrap
 	templatePath := filepath.Join(dir, pb.Template)
 
-	if err := cfnvalidate.PreBootstrapTemplate(templatePath); err != nil {
-		return nil, errors.Wrap(err, "validating pre-bootstrap template")
-	}
-
-	params, err := cfnparams.Resolve(pb.Parameters, cctx.ContextValues)
+	params, err := cfnparams.ResolveSources(ctx, pb.Parameters, preBootstrapSources(dir, cctx, profile))
 	if err != nil {
 		return nil, errors.Wrap(err, "resolving pre-bootstrap parameters")
 	}
 
+	if err := cfnvalidate.PreBootstrapTemplate(templatePath, params); err != nil {
+		return nil, errors.Wrap(err, "validating pre-bootstrap template")
+	}
+
 	stackName := cctx.Qualifier + "-pre-bootstrap"
 
 	fmt.Fprintf(os.Stderr, "Deploying pre-bootstrap stack %s...\n", stackName)
@@ -469,15 +613,28 @@ func filterProfileArgs(args []string) []string {
 	return filtered
 }
 
-func patchedBootstrapTemplate(ctx context.Context, dir string) (string, error) {
+func patchedBootstrapTemplate(ctx context.Context, cfg *cdkConfig, dir string) ([]byte, error) {
+	patches, err := cfg.bootstrapPatches()
+	if err != nil {
+		return nil, err
+	}
+
 	templateYAML, err := cmdexec.Output(ctx, dir, "cdk", "bootstrap", "--show-template")
 	if err != nil {
-		return "", errors.Wrap(err, "getting default bootstrap template")
+		return nil, errors.Wrap(err, "getting default bootstrap template")
+	}
+
+	patched, err := cfnpatch.Apply([]byte(templateYAML), patches...)
+	if err != nil {
+		return nil, errors.Wrap(err, "patching bootstrap template")
 	}
+	return patched, nil
+}
 
-	patched, err := cfnpatch.AddDevSlotLifecycle([]byte(templateYAML), devSlotExpirationDays)
+func writeBootstrapTemplate(ctx context.Context, cfg *cdkConfig, dir string) (string, error) {
+	patched, err := patchedBootstrapTemplate(ctx, cfg, dir)
 	if err != nil {
-		return "", errors.Wrap(err, "patching bootstrap template")
+		return "", err
 	}
 
 	tmpFile, err := os.CreateTemp("", "cdk-bootstrap-*.yaml")
@@ -0,0 +1,87 @@
+package cdktool
+
+import (
+	"context"
+	"strings"
+
+	"github.com/basewarphq/bw/cmd/internal/cdkctx"
+	"github.com/basewarphq/bw/cmd/internal/cfnread"
+	"github.com/basewarphq/bw/cmd/internal/parallel"
+	"github.com/basewarphq/bw/cmd/internal/tool"
+	"github.com/cockroachdb/errors"
+)
+
+// ContextDir returns the directory holding cdk.json and cdk.context.json.
+func ContextDir(toolCfg any, projectDir string) string {
+	cfg, _ := toolCfg.(cdkConfig)
+	return cfg.resolveDir(projectDir)
+}
+
+// checkContext reports the context errors that `cdk synth` would panic with,
+// without starting the CDK app.
+func checkContext(dir string, r tool.NodeReporter) error {
+	cctx, err := cdkctx.Load(dir)
+	if err == nil {
+		err = cctx.Validate()
+	}
+	if err != nil {
+		r.Error("✗ cdk context: " + err.Error())
+		return err
+	}
+	r.Table(nil, [][]string{{"✓", "cdk context"}})
+	return nil
+}
+
+// RemoveDeployment removes a deployment from cdk.context.json. It refuses
+// while any stack of the deployment still exists, since those stacks would
+// be orphaned: bw no longer knows the deployment to destroy them.
+func RemoveDeployment(ctx context.Context, toolCfg any, projectDir, deployment string) error {
+	cfg, _ := toolCfg.(cdkConfig)
+	dir := cfg.resolveDir(projectDir)
+
+	cctx, err := cdkctx.Load(dir)
+	if err != nil {
+		return err
+	}
+	if !cctx.IsValidDeployment(deployment) {
+		return errors.Newf("unknown deployment %q", deployment)
+	}
+
+	profile := cfg.forDeployment(cctx, deployment).Profile
+	deployed, err := deployedStacks(ctx, profile, cctx.DeploymentStacks(deployment))
+	if err != nil {
+		return err
+	}
+	if len(deployed) > 0 {
+		return errors.Newf("deployment %s still has deployed stacks (%s); run 'bw infra destroy %s' first",
+			deployment, strings.Join(deployed, ", "), deployment)
+	}
+	return cdkctx.RemoveDeployment(dir, deployment)
+}
+
+// deployedStacks returns the names of the stacks that exist in CloudFormation.
+func deployedStacks(ctx context.Context, profile string, stacks []cdkctx.StackRef) ([]string, error) {
+	exists := make([]bool, len(stacks))
+	errs := make([]error, len(stacks))
+	parallel.ForEach(len(stacks), len(stacks), func(i int) {
+		_, err := cfnread.DescribeStack(ctx, stacks[i].Region, profile, stacks[i].Name)
+		switch {
+		case errors.Is(err, cfnread.ErrStackNotFound):
+		case err != nil:
+			errs[i] = errors.Wrapf(err, "checking %s", stacks[i].Name)
+		default:
+			exists[i] = true
+		}
+	})
+
+	var names []string
+	for i, stack := range stacks {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		if exists[i] {
+			names = append(names, stack.Name)
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,116 @@
+package cdktool
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/basewarphq/bw/bwcdk/bwcdkutil"
+	"github.com/basewarphq/bw/cmd/internal/cdkctx"
+	"github.com/basewarphq/bw/cmd/internal/cdkmanifest"
+	"github.com/basewarphq/bw/cmd/internal/cmdexec"
+	"github.com/basewarphq/bw/cmd/internal/devslot"
+	"github.com/basewarphq/bw/cmd/internal/tool"
+	"github.com/cockroachdb/errors"
+)
+
+// prodDestroyEnv must hold the deployment name, in addition to the
+// --i-know-this-is-prod flag, before a production deployment is destroyed.
+const prodDestroyEnv = "BW_I_KNOW_THIS_IS_PROD"
+
+func (t *Tool) Destroy(ctx context.Context, dir string, _ tool.NodeReporter) error {
+	cfg := configFromCtx(ctx)
+	dir = cfg.resolveDir(dir)
+	opts, _ := tool.DestroyOptionsFrom(ctx)
+
+	deployment, ok := tool.DeploymentFrom(ctx)
+	if !ok || deployment == "" {
+		return errors.New("destroy requires an explicit deployment name")
+	}
+
+	cctx, err := cdkctx.Load(dir)
+	if err != nil {
+		return err
+	}
+	if !cctx.IsValidDeployment(deployment) {
+		return errors.Newf("unknown deployment %q", deployment)
+	}
+	cfg = cfg.forDeployment(cctx, deployment)
+
+	if err := checkProdDestroy(deployment, opts, os.Getenv(prodDestroyEnv)); err != nil {
+		return err
+	}
+
+	app, err := deploymentStacks(ctx, cfg, cctx, dir, deployment)
+	if err != nil {
+		return err
+	}
+	stacks := destroyOrder(app)
+	fmt.Fprintf(os.Stderr, "This destroys deployment %s:\n", deployment)
+	for _, stack := range stacks {
+		fmt.Fprintf(os.Stderr, "  %s (%s)\n", stack.Name, stack.Region)
+	}
+	if err := confirmDestroy(deployment, opts, os.Stdin, os.Stderr); err != nil {
+		return err
+	}
+
+	for _, stack := range stacks {
+		fmt.Fprintf(os.Stderr, "Destroying %s (%s)...\n", stack.Name, stack.Region)
+		args := []string{"destroy", "--force"}
+		args = append(args, cfg.cdkArgs(cctx.Qualifier)...)
+		args = append(args, app.cdkArgs([]*cdkmanifest.Stack{stack})...)
+		if err := cmdexec.Run(ctx, dir, "cdk", args...); err != nil {
+			return errors.Wrapf(err, "destroying %s", stack.Name)
+		}
+	}
+	if err := devslot.ReleaseDestroyed(ctx, dir, cfg.slotSettings(), deployment); err != nil {
+		return errors.Wrapf(err, "releasing slot %s after destroying its stacks", deployment)
+	}
+	return nil
+}
+
+// destroyOrder returns the deployment stacks in reverse dependency order, so
+// stacks go before the stacks they depend on. Shared stacks are left alone
+// because other deployments still use them.
+func destroyOrder(app appStacks) []*cdkmanifest.Stack {
+	stacks := slices.Clone(app.Deployment)
+	slices.Reverse(stacks)
+	return stacks
+}
+
+func checkProdDestroy(deployment string, opts tool.DestroyOptions, env string) error {
+	if !bwcdkutil.IsProdDeployment(deployment) {
+		return nil
+	}
+	if !opts.IKnowThisIsProd {
+		return errors.Newf("refusing to destroy production deployment %s without --i-know-this-is-prod", deployment)
+	}
+	if env != deployment {
+		return errors.Newf("refusing to destroy production deployment %s: set %s=%s to confirm",
+			deployment, prodDestroyEnv, deployment)
+	}
+	return nil
+}
+
+func confirmDestroy(deployment string, opts tool.DestroyOptions, in io.Reader, out io.Writer) error {
+	if opts.Confirm != "" {
+		if opts.Confirm != deployment {
+			return errors.Newf("--confirm %q does not match deployment %q", opts.Confirm, deployment)
+		}
+		return nil
+	}
+
+	fmt.Fprintf(out, "Type the deployment name (%s) to confirm: ", deployment)
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return errors.Wrap(err, "reading confirmation")
+	}
+	if strings.TrimSpace(line) != deployment {
+		return errors.New("confirmation did not match, nothing was destroyed")
+	}
+	return nil
+}
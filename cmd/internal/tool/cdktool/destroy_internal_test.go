@@ -0,0 +1,49 @@
+package cdktool
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/basewarphq/bw/cmd/internal/tool"
+)
+
+func TestCheckProdDestroy(t *testing.T) {
+	t.Parallel()
+
+	if err := checkProdDestroy("Dev03", tool.DestroyOptions{}, ""); err != nil {
+		t.Errorf("non-prod deployments need no extra guard, got %v", err)
+	}
+	if err := checkProdDestroy("Prod", tool.DestroyOptions{}, "Prod"); err == nil {
+		t.Error("expected refusal without --i-know-this-is-prod")
+	}
+	if err := checkProdDestroy("Prod", tool.DestroyOptions{IKnowThisIsProd: true}, ""); err == nil {
+		t.Error("expected refusal without the env var")
+	}
+	if err := checkProdDestroy("Prod", tool.DestroyOptions{IKnowThisIsProd: true}, "Stag"); err == nil {
+		t.Error("expected refusal when the env var names another deployment")
+	}
+	if err := checkProdDestroy("Prod", tool.DestroyOptions{IKnowThisIsProd: true}, "Prod"); err != nil {
+		t.Errorf("expected flag and env var to allow destroy, got %v", err)
+	}
+}
+
+func TestConfirmDestroy(t *testing.T) {
+	t.Parallel()
+
+	if err := confirmDestroy("Dev03", tool.DestroyOptions{}, strings.NewReader("Dev03\n"), io.Discard); err != nil {
+		t.Errorf("typed name should confirm, got %v", err)
+	}
+	if err := confirmDestroy("Dev03", tool.DestroyOptions{}, strings.NewReader("dev03\n"), io.Discard); err == nil {
+		t.Error("confirmation must match exactly")
+	}
+	if err := confirmDestroy("Dev03", tool.DestroyOptions{}, strings.NewReader(""), io.Discard); err == nil {
+		t.Error("empty input must not confirm")
+	}
+	if err := confirmDestroy("Dev03", tool.DestroyOptions{Confirm: "Dev03"}, strings.NewReader(""), io.Discard); err != nil {
+		t.Errorf("--confirm should skip the prompt, got %v", err)
+	}
+	if err := confirmDestroy("Dev03", tool.DestroyOptions{Confirm: "Dev04"}, strings.NewReader(""), io.Discard); err == nil {
+		t.Error("mismatched --confirm must fail")
+	}
+}
@@ -0,0 +1,72 @@
+package cdktool
+
+import (
+	"encoding/json"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/basewarphq/bw/cmd/internal/cdkdiff"
+	"github.com/basewarphq/bw/cmd/internal/tool"
+	"github.com/cockroachdb/errors"
+)
+
+func reportDiff(stacks []cdkdiff.StackDiff, opts tool.DiffOptions, r tool.NodeReporter) error {
+	if opts.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(stacks); err != nil {
+			return errors.Wrap(err, "encoding diff summary")
+		}
+	} else {
+		reportDiffTables(stacks, r)
+	}
+
+	if !opts.FailOnReplacement {
+		return nil
+	}
+	var replaced []string
+	for _, s := range stacks {
+		for _, rc := range s.Replacements() {
+			replaced = append(replaced, s.Stack+"/"+rc.LogicalID)
+		}
+	}
+	if len(replaced) > 0 {
+		return errors.Newf("diff replaces %d resource(s): %s", len(replaced), strings.Join(replaced, ", "))
+	}
+	return nil
+}
+
+func reportDiffTables(stacks []cdkdiff.StackDiff, r tool.NodeReporter) {
+	rows := make([][]string, 0, len(stacks))
+	var risky, iam [][]string
+	for _, s := range stacks {
+		rows = append(rows, []string{
+			s.Stack,
+			strconv.Itoa(s.Added),
+			strconv.Itoa(s.Modified),
+			strconv.Itoa(s.Replaced),
+			strconv.Itoa(s.Removed),
+			strconv.Itoa(len(s.IAM)),
+		})
+		for _, rc := range s.Resources {
+			if rc.Risky() {
+				risky = append(risky, []string{s.Stack, string(rc.Change), rc.Type, rc.LogicalID})
+			}
+		}
+		for _, c := range s.IAM {
+			iam = append(iam, []string{s.Stack, string(c.Change), c.Detail})
+		}
+	}
+
+	r.Section("Diff summary")
+	r.Table([]string{"Stack", "Added", "Modified", "Replaced", "Removed", "IAM"}, rows)
+	if len(risky) > 0 {
+		r.Section("Stateful resources replaced or removed")
+		r.Table([]string{"Stack", "Change", "Type", "LogicalID"}, risky)
+	}
+	if len(iam) > 0 {
+		r.Section("IAM changes")
+		r.Table([]string{"Stack", "Change", "Detail"}, iam)
+	}
+}
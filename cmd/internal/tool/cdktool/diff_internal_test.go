@@ -0,0 +1,45 @@
+package cdktool
+
+import (
+	"testing"
+
+	"github.com/basewarphq/bw/cmd/internal/cdkdiff"
+	"github.com/basewarphq/bw/cmd/internal/tool"
+)
+
+type recordingReporter struct {
+	sections []string
+}
+
+func (r *recordingReporter) Section(heading string)     { r.sections = append(r.sections, heading) }
+func (r *recordingReporter) Table([]string, [][]string) {}
+func (r *recordingReporter) Error(string)               {}
+
+func TestReportDiff_FailOnReplacement(t *testing.T) {
+	t.Parallel()
+
+	stacks := []cdkdiff.StackDiff{{
+		Stack:    "bwappEucDev03",
+		Replaced: 1,
+		Resources: []cdkdiff.ResourceChange{
+			{Change: cdkdiff.ChangeReplace, Type: "AWS::DynamoDB::Table", LogicalID: "Table", Stateful: true},
+		},
+	}}
+
+	r := &recordingReporter{}
+	if err := reportDiff(stacks, tool.DiffOptions{}, r); err != nil {
+		t.Errorf("replacements should not fail without the flag, got %v", err)
+	}
+	if len(r.sections) != 2 {
+		t.Errorf("expected summary and stateful sections, got %v", r.sections)
+	}
+
+	if err := reportDiff(stacks, tool.DiffOptions{FailOnReplacement: true}, &recordingReporter{}); err == nil {
+		t.Error("expected --fail-on-replacement to fail")
+	}
+
+	clean := []cdkdiff.StackDiff{{Stack: "bwappEucDev03", Added: 1}}
+	if err := reportDiff(clean, tool.DiffOptions{FailOnReplacement: true}, &recordingReporter{}); err != nil {
+		t.Errorf("diff without replacements should pass, got %v", err)
+	}
+}
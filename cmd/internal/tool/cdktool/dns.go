@@ -0,0 +1,114 @@
+package cdktool
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/basewarphq/bw/bwcdk/bwcdkdns"
+	"github.com/basewarphq/bw/cmd/internal/cdkctx"
+	"github.com/basewarphq/bw/cmd/internal/cfnread"
+	"github.com/basewarphq/bw/cmd/internal/dnscheck"
+	"github.com/basewarphq/bw/cmd/internal/tool"
+	"github.com/cockroachdb/errors"
+)
+
+// DNSStatus is the outcome of checking the base domain's delegation.
+type DNSStatus struct {
+	Delegated bool
+	// FlagSet reports whether {prefix}dns-delegated is already true.
+	FlagSet bool
+	FlagKey string
+	cdkDir  string
+}
+
+// CheckDNS resolves the NS records of the base domain on public resolvers,
+// compares them with the hosted zone of the primary shared stack and reports
+// the result.
+func CheckDNS(ctx context.Context, toolCfg any, projectDir string, r tool.NodeReporter) (*DNSStatus, error) {
+	cfg, _ := toolCfg.(cdkConfig)
+	return checkDNS(ctx, &cfg, cfg.resolveDir(projectDir), r)
+}
+
+// MarkDNSDelegated sets {prefix}dns-delegated to true in cdk.context.json.
+func MarkDNSDelegated(status *DNSStatus) error {
+	return cdkctx.SetValue(status.cdkDir, status.FlagKey, true)
+}
+
+func checkDNS(ctx context.Context, cfg *cdkConfig, dir string, r tool.NodeReporter) (*DNSStatus, error) {
+	cctx, err := cdkctx.Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	domain := cctx.ContextValues["base-domain-name"]
+	if domain == "" {
+		return nil, errors.Newf("context key %q is not set", cctx.Prefix+"base-domain-name")
+	}
+
+	shared := cctx.SharedStacks()[0]
+	outputs, err := cfnread.StackOutputs(ctx, shared.Region, cfg.Profile, shared.Name)
+	if err != nil {
+		return nil, err
+	}
+	nameServers := outputs[bwcdkdns.NameServersOutputKey]
+	if nameServers == "" {
+		return nil, errors.Newf("%s has no %s output; deploy the shared stack first",
+			shared.Name, bwcdkdns.NameServersOutputKey)
+	}
+
+	report := dnscheck.Check(ctx, domain, strings.Split(nameServers, ","), dnscheck.PublicResolvers)
+
+	rows := [][]string{{"hosted zone", strings.Join(report.Expected, " "), ""}}
+	for _, res := range report.Results {
+		match := "yes"
+		servers := strings.Join(res.NameServers, " ")
+		switch {
+		case res.Err != nil:
+			match, servers = "error", res.Err.Error()
+		case !res.Matches(report.Expected):
+			match = "no"
+		}
+		rows = append(rows, []string{res.Resolver, servers, match})
+	}
+	r.Section("NS records of " + domain)
+	r.Table([]string{"Source", "Name servers", "Matches"}, rows)
+
+	status := &DNSStatus{
+		Delegated: report.Delegated(),
+		FlagSet:   cctx.DNSDelegated,
+		FlagKey:   cctx.Prefix + "dns-delegated",
+		cdkDir:    dir,
+	}
+	r.Table([]string{"Check", "Result"}, [][]string{
+		{"Delegation complete", yesNo(status.Delegated)},
+		{status.FlagKey, fmt.Sprint(status.FlagSet)},
+	})
+	return status, nil
+}
+
+func inspectDNS(ctx context.Context, dir string, r tool.NodeReporter) error {
+	cfg := configFromCtx(ctx)
+	opts, _ := tool.InspectOptionsFrom(ctx)
+
+	status, err := checkDNS(ctx, cfg, cfg.resolveDir(dir), r)
+	if err != nil {
+		return err
+	}
+	switch {
+	case status.Delegated && !status.FlagSet:
+		r.Error("delegation is complete; run 'bw infra dns check' to set " + status.FlagKey)
+	case !status.Delegated && status.FlagSet:
+		r.Error(status.FlagKey + " is true but public resolvers do not see the hosted zone's name servers")
+	}
+	if opts.Strict && !status.Delegated {
+		return errors.New("DNS delegation is not complete")
+	}
+	return nil
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,130 @@
+package cdktool
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/basewarphq/bw/cmd/internal/cdkctx"
+	"github.com/basewarphq/bw/cmd/internal/cfnread"
+	"github.com/basewarphq/bw/cmd/internal/cmdexec"
+	"github.com/basewarphq/bw/cmd/internal/parallel"
+	"github.com/basewarphq/bw/cmd/internal/tool"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	driftConcurrency = 8
+	// driftValueWidth keeps property values, which are often whole JSON
+	// documents, readable in a table.
+	driftValueWidth = 60
+)
+
+type stackDrift struct {
+	stack     cdkctx.StackRef
+	status    string
+	resources []cfnread.ResourceDrift
+	err       error
+}
+
+func inspectDrift(ctx context.Context, dir string, r tool.NodeReporter) error {
+	cfg := configFromCtx(ctx)
+	dir = cfg.resolveDir(dir)
+	opts, _ := tool.InspectOptionsFrom(ctx)
+
+	deployment, err := resolveDeployment(ctx, cfg, dir)
+	if err != nil {
+		return err
+	}
+	cctx, err := cdkctx.Load(dir)
+	if err != nil {
+		return err
+	}
+	cfg = cfg.forDeployment(cctx, deployment)
+
+	app, err := deploymentStacks(ctx, cfg, cctx, dir, deployment)
+	if err != nil {
+		return err
+	}
+	stacks := app.Refs()
+	results := make([]stackDrift, len(stacks))
+	parallel.ForEach(len(stacks), driftConcurrency, func(i int) {
+		results[i] = detectDrift(ctx, cfg.Profile, stacks[i])
+	})
+
+	rows := make([][]string, 0, len(results))
+	var drifted []string
+	for _, res := range results {
+		status := res.status
+		if res.err != nil {
+			status = "error: " + cmdexec.Summary(res.err)
+		}
+		rows = append(rows, []string{res.stack.Name, res.stack.Region, status})
+		if res.status == "DRIFTED" {
+			drifted = append(drifted, res.stack.Name)
+		}
+	}
+	r.Table([]string{"Stack", "Region", "Drift"}, rows)
+
+	for _, res := range results {
+		if len(res.resources) == 0 {
+			continue
+		}
+		var diffRows [][]string
+		for _, rd := range res.resources {
+			if len(rd.Differences) == 0 {
+				diffRows = append(diffRows, []string{rd.LogicalID, rd.ResourceType, rd.Status, "", "", ""})
+			}
+			for _, d := range rd.Differences {
+				diffRows = append(diffRows, []string{
+					rd.LogicalID, rd.ResourceType, d.Type, d.Path,
+					truncate(d.Expected, driftValueWidth), truncate(d.Actual, driftValueWidth),
+				})
+			}
+		}
+		r.Section(fmt.Sprintf("%s (%s)", res.stack.Name, res.stack.Region))
+		r.Table([]string{"Resource", "Type", "Change", "Property", "Expected", "Actual"}, diffRows)
+	}
+
+	if !opts.Strict {
+		return nil
+	}
+	if len(drifted) > 0 {
+		return errors.Newf("%d stack(s) drifted: %s", len(drifted), strings.Join(drifted, ", "))
+	}
+	// A stack whose drift could not be checked must not pass a strict run.
+	for _, res := range results {
+		if res.err != nil {
+			return errors.Wrapf(res.err, "drift detection for %s", res.stack.Name)
+		}
+	}
+	return nil
+}
+
+func detectDrift(ctx context.Context, profile string, stack cdkctx.StackRef) stackDrift {
+	res := stackDrift{stack: stack}
+	status, err := cfnread.DetectStackDrift(ctx, stack.Region, profile, stack.Name)
+	if errors.Is(err, cfnread.ErrStackNotFound) {
+		res.status = "not deployed"
+		return res
+	}
+	if err != nil {
+		res.err = err
+		return res
+	}
+	res.status = status
+	if status == "DRIFTED" {
+		res.resources, res.err = cfnread.StackResourceDrifts(ctx, stack.Region, profile, stack.Name)
+	}
+	return res
+}
+
+// truncate shortens s to n runes, so multi-byte characters in property
+// values are never cut in half.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-3]) + "..."
+}
@@ -0,0 +1,21 @@
+package cdktool
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncate(t *testing.T) {
+	t.Parallel()
+
+	if got := truncate("short", 10); got != "short" {
+		t.Errorf("got %q, want unchanged", got)
+	}
+	if got := truncate("abcdefghij", 8); got != "abcde..." {
+		t.Errorf("got %q, want %q", got, "abcde...")
+	}
+	got := truncate("ääääääääää", 8)
+	if !utf8.ValidString(got) || got != "äääää..." {
+		t.Errorf("got %q, want %q", got, "äääää...")
+	}
+}
@@ -0,0 +1,118 @@
+package cdktool
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/basewarphq/bw/cmd/internal/cdkctx"
+	"github.com/basewarphq/bw/cmd/internal/cfnread"
+	"github.com/basewarphq/bw/cmd/internal/healthcheck"
+	"github.com/basewarphq/bw/cmd/internal/parallel"
+	"github.com/basewarphq/bw/cmd/internal/tool"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	healthConcurrency = 8
+	// certWarnWithin flags certificates that ACM should already have renewed.
+	certWarnWithin = 14 * 24 * time.Hour
+)
+
+type healthTarget struct {
+	output string
+	region string
+	url    string
+}
+
+func inspectHealth(ctx context.Context, dir string, r tool.NodeReporter) error {
+	cfg := configFromCtx(ctx)
+	dir = cfg.resolveDir(dir)
+	opts, _ := tool.InspectOptionsFrom(ctx)
+
+	deployment, err := resolveDeployment(ctx, cfg, dir)
+	if err != nil {
+		return err
+	}
+	cctx, err := cdkctx.Load(dir)
+	if err != nil {
+		return err
+	}
+	cfg = cfg.forDeployment(cctx, deployment)
+
+	app, err := deploymentStacks(ctx, cfg, cctx, dir, deployment)
+	if err != nil {
+		return err
+	}
+	prefetchStacks(ctx, cfg, cctx)
+
+	var targets []healthTarget
+	for _, stack := range app.Deployment {
+		if !declaresOutput(stack, "GatewayURL") {
+			continue
+		}
+		outputs, err := cfnread.StackOutputs(ctx, stack.Region, cfg.Profile, stack.Name)
+		if errors.Is(err, cfnread.ErrStackNotFound) {
+			r.Error(fmt.Sprintf("%s: (not deployed)", stack.Name))
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for key, url := range outputs {
+			if strings.Contains(key, "GatewayURL") {
+				targets = append(targets, healthTarget{output: key, region: stack.Region, url: url})
+			}
+		}
+	}
+	if len(targets) == 0 {
+		r.Error("no gateway URL outputs found")
+		return nil
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		if targets[i].output != targets[j].output {
+			return targets[i].output < targets[j].output
+		}
+		return targets[i].region < targets[j].region
+	})
+
+	client := &http.Client{Timeout: healthcheck.Timeout}
+	results := make([]healthcheck.Result, len(targets))
+	parallel.ForEach(len(targets), healthConcurrency, func(i int) {
+		results[i] = healthcheck.Check(ctx, client, healthcheck.HealthURL(targets[i].url))
+	})
+
+	now := time.Now()
+	var failed []string
+	rows := make([][]string, 0, len(targets))
+	for i, res := range results {
+		status, latency, cert := "-", "-", "-"
+		switch {
+		case res.Err != nil:
+			status = "error: " + res.Err.Error()
+		default:
+			status = strconv.Itoa(res.Status)
+			latency = res.Latency.Round(time.Millisecond).String()
+		}
+		if !res.CertExpiry.IsZero() {
+			days := int(res.CertExpiry.Sub(now).Hours() / 24)
+			cert = fmt.Sprintf("%s (%dd)", res.CertExpiry.Format("2006-01-02"), days)
+		}
+		if !res.OK() || (!res.CertExpiry.IsZero() && res.CertExpiry.Sub(now) < certWarnWithin) {
+			failed = append(failed, targets[i].output+"@"+targets[i].region)
+		}
+		rows = append(rows, []string{
+			targets[i].output, targets[i].region, res.URL, status, latency, cert, strings.Join(res.Addrs, " "),
+		})
+	}
+	r.Table([]string{"Output", "Region", "URL", "Status", "Latency", "Cert expires", "DNS"}, rows)
+
+	if opts.Strict && len(failed) > 0 {
+		return errors.Newf("%d endpoint(s) unhealthy: %s", len(failed), strings.Join(failed, ", "))
+	}
+	return nil
+}
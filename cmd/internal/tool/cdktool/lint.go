@@ -0,0 +1,53 @@
+package cdktool
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/basewarphq/bw/cmd/internal/cfnvalidate"
+	"github.com/basewarphq/bw/cmd/internal/tool"
+	"github.com/cockroachdb/errors"
+)
+
+// Lint checks the pre-bootstrap template and the templates of the last
+// synth in cdk.out. It does not synthesize, so a missing cdk.out is fine.
+// Parameter values are not resolved here; bootstrap checks them before it
+// deploys the pre-bootstrap stack.
+func (t *Tool) Lint(ctx context.Context, dir string, r tool.NodeReporter) error {
+	cfg := configFromCtx(ctx)
+	dir = cfg.resolveDir(dir)
+
+	var findings []cfnvalidate.Finding
+	if pb := cfg.PreBootstrap; pb != nil {
+		fs, err := cfnvalidate.Lint(filepath.Join(dir, pb.Template), cfnvalidate.Options{PreBootstrap: true})
+		if err != nil {
+			return errors.Wrap(err, "linting pre-bootstrap template")
+		}
+		findings = append(findings, fs...)
+	}
+
+	cdkOut := filepath.Join(dir, "cdk.out")
+	if _, err := os.Stat(cdkOut); err == nil {
+		fs, err := cfnvalidate.CloudAssembly(cdkOut)
+		if err != nil {
+			return err
+		}
+		findings = append(findings, fs...)
+	}
+
+	if len(findings) == 0 {
+		return nil
+	}
+	rows := make([][]string, 0, len(findings))
+	for _, f := range findings {
+		location := f.File
+		if rel, err := filepath.Rel(dir, f.File); err == nil {
+			location = rel
+		}
+		rows = append(rows, []string{string(f.Severity), fmt.Sprintf("%s:%d", location, f.Line), f.Message})
+	}
+	r.Table([]string{"Severity", "Location", "Message"}, rows)
+	return cfnvalidate.Errors(findings)
+}
@@ -0,0 +1,91 @@
+package cdktool
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/basewarphq/bw/cmd/internal/cdkctx"
+	"github.com/basewarphq/bw/cmd/internal/cfnread"
+	"github.com/basewarphq/bw/cmd/internal/cwlogs"
+	"github.com/basewarphq/bw/cmd/internal/stackoutputs"
+	"github.com/basewarphq/bw/cmd/internal/tool"
+	"github.com/cockroachdb/errors"
+)
+
+// Outputs collects the stack outputs of a deployment of the cdk project in
+// projectDir. An empty deployment resolves the same way deploy does, so it
+// defaults to the developer's claimed slot.
+func Outputs(ctx context.Context, toolCfg any, projectDir, deployment string) ([]stackoutputs.Output, error) {
+	cfg, _ := toolCfg.(cdkConfig)
+	dir := cfg.resolveDir(projectDir)
+	if deployment != "" {
+		ctx = tool.WithDeployment(ctx, deployment)
+	}
+
+	deployment, err := resolveDeployment(ctx, &cfg, dir)
+	if err != nil {
+		return nil, err
+	}
+	cctx, err := cdkctx.Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !cctx.IsValidDeployment(deployment) {
+		return nil, errors.Newf("unknown deployment %q", deployment)
+	}
+	return stackoutputs.Collect(ctx, cctx, cfg.forDeployment(cctx, deployment).Profile, deployment)
+}
+
+// LogGroups returns the log groups that the deployment's stacks export as
+// "*LogGroup" outputs, in every region. Labels drop the "LogGroup" suffix and
+// carry the region ident when the app spans several regions.
+func LogGroups(ctx context.Context, toolCfg any, projectDir, deployment string) ([]*cwlogs.Group, error) {
+	cfg, _ := toolCfg.(cdkConfig)
+	dir := cfg.resolveDir(projectDir)
+	if deployment != "" {
+		ctx = tool.WithDeployment(ctx, deployment)
+	}
+
+	deployment, err := resolveDeployment(ctx, &cfg, dir)
+	if err != nil {
+		return nil, err
+	}
+	cctx, err := cdkctx.Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !cctx.IsValidDeployment(deployment) {
+		return nil, errors.Newf("unknown deployment %q", deployment)
+	}
+
+	profile := cfg.forDeployment(cctx, deployment).Profile
+	multiRegion := len(cctx.SecondaryRegions) > 0
+	var groups []*cwlogs.Group
+	for _, stack := range cctx.DeploymentStacks(deployment) {
+		outputs, err := cfnread.StackOutputs(ctx, stack.Region, profile, stack.Name)
+		if errors.Is(err, cfnread.ErrStackNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for key, name := range outputs {
+			label, ok := strings.CutSuffix(key, "LogGroup")
+			if !ok || label == "" {
+				continue
+			}
+			if multiRegion {
+				label += "@" + cctx.RegionIdent(stack.Region)
+			}
+			groups = append(groups, &cwlogs.Group{
+				Label:   label,
+				Name:    name,
+				Region:  stack.Region,
+				Profile: profile,
+			})
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Label < groups[j].Label })
+	return groups, nil
+}
@@ -0,0 +1,60 @@
+package cdktool
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/basewarphq/bw/cmd/internal/cdkctx"
+	"github.com/basewarphq/bw/cmd/internal/cfnparams"
+	"github.com/basewarphq/bw/cmd/internal/cmdexec"
+	"github.com/basewarphq/bw/cmd/internal/devslot"
+	"github.com/cockroachdb/errors"
+)
+
+// preBootstrapSources resolves pre-bootstrap parameter placeholders against
+// the primary region of the account the profile points at.
+func preBootstrapSources(dir string, cctx *cdkctx.CDKContext, profile string) cfnparams.Sources {
+	return cfnparams.Sources{
+		Context:   cctx.ContextValues,
+		LookupEnv: os.LookupEnv,
+		GetParameter: func(ctx context.Context, name string) (string, error) {
+			return ssmParameter(ctx, cctx.PrimaryRegion, profile, name)
+		},
+		ReadSecret: func(ctx context.Context, ref string) (string, error) {
+			out, err := cmdexec.Output(ctx, dir, "op", "read", "--no-newline", ref)
+			if err != nil {
+				return "", errors.New(cmdexec.Summary(err))
+			}
+			return out, nil
+		},
+		Account: func(ctx context.Context) (string, error) {
+			return devslot.AccountID(ctx, profile)
+		},
+		Region: cctx.PrimaryRegion,
+	}
+}
+
+func ssmParameter(ctx context.Context, region, profile, name string) (string, error) {
+	args := []string{
+		"ssm", "get-parameter",
+		"--no-cli-pager",
+		"--region", region,
+		"--name", name,
+		"--with-decryption",
+		"--query", "Parameter.Value",
+		"--output", "text",
+	}
+	if profile != "" {
+		args = append(args, "--profile", profile)
+	}
+	out, err := cmdexec.Output(ctx, "/", "aws", args...)
+	if err != nil {
+		var cmdErr *cmdexec.Error
+		if errors.As(err, &cmdErr) && strings.Contains(cmdErr.Stderr, "ParameterNotFound") {
+			return "", cfnparams.ErrNotFound
+		}
+		return "", errors.New(cmdexec.Summary(err))
+	}
+	return strings.TrimSuffix(out, "\n"), nil
+}
@@ -0,0 +1,87 @@
+package cdktool
+
+import (
+	"encoding/json"
+
+	"github.com/basewarphq/bw/cmd/internal/cfnpatch"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	patchDevSlotLifecycle    = "dev-slot-lifecycle"
+	patchBucketAccessLogging = "bucket-access-logging"
+	patchKMSKeyRotation      = "kms-key-rotation"
+	patchTags                = "tags"
+	patchECRLifecycle        = "ecr-lifecycle"
+)
+
+// patchConfig is one [[project.tool.cdk.bootstrap-patches]] entry. Which
+// fields apply depends on the type.
+type patchConfig struct {
+	Type   string            `toml:"type"`
+	Days   int               `toml:"days"`
+	Bucket string            `toml:"bucket"`
+	Prefix string            `toml:"prefix"`
+	Tags   map[string]string `toml:"tags"`
+	Policy string            `toml:"policy"`
+}
+
+func (p patchConfig) patch() (cfnpatch.Patch, error) {
+	switch p.Type {
+	case patchDevSlotLifecycle:
+		days := p.Days
+		if days == 0 {
+			days = cfnpatch.DefaultDevSlotExpirationDays
+		}
+		if days < 0 {
+			return nil, errors.Newf("days must be positive, got %d", days)
+		}
+		return cfnpatch.DevSlotLifecycle{ExpirationDays: days}, nil
+	case patchBucketAccessLogging:
+		if p.Bucket == "" {
+			return nil, errors.New("bucket is required")
+		}
+		return cfnpatch.BucketAccessLogging{TargetBucket: p.Bucket, Prefix: p.Prefix}, nil
+	case patchKMSKeyRotation:
+		return cfnpatch.KMSKeyRotation{}, nil
+	case patchTags:
+		if len(p.Tags) == 0 {
+			return nil, errors.New("tags must not be empty")
+		}
+		return cfnpatch.Tags{Tags: p.Tags}, nil
+	case patchECRLifecycle:
+		if !json.Valid([]byte(p.Policy)) {
+			return nil, errors.New("policy must be a JSON ECR lifecycle policy")
+		}
+		return cfnpatch.ECRLifecycle{PolicyText: p.Policy}, nil
+	default:
+		return nil, errors.Newf("unknown patch type %q", p.Type)
+	}
+}
+
+// bootstrapPatches returns the configured patches in order. Slot locks rely
+// on the dev-slot lifecycle rule, so it is always applied with the default
+// expiration when the config does not list it.
+func (c *cdkConfig) bootstrapPatches() ([]cfnpatch.Patch, error) {
+	patches := make([]cfnpatch.Patch, 0, len(c.BootstrapPatches)+1)
+	hasDevSlot := false
+	for i, pc := range c.BootstrapPatches {
+		p, err := pc.patch()
+		if err != nil {
+			return nil, errors.Wrapf(err, "bootstrap-patches[%d]", i)
+		}
+		if pc.Type == patchDevSlotLifecycle {
+			if hasDevSlot {
+				return nil, errors.Newf("bootstrap-patches[%d]: %s is listed twice", i, pc.Type)
+			}
+			hasDevSlot = true
+		}
+		patches = append(patches, p)
+	}
+	if !hasDevSlot {
+		patches = append([]cfnpatch.Patch{
+			cfnpatch.DevSlotLifecycle{ExpirationDays: cfnpatch.DefaultDevSlotExpirationDays},
+		}, patches...)
+	}
+	return patches, nil
+}
@@ -0,0 +1,48 @@
+package cdktool
+
+import (
+	"testing"
+
+	"github.com/basewarphq/bw/cmd/internal/cfnpatch"
+)
+
+func TestBootstrapPatches(t *testing.T) {
+	t.Parallel()
+
+	cfg := cdkConfig{BootstrapPatches: []patchConfig{{Type: patchKMSKeyRotation}}}
+	patches, err := cfg.bootstrapPatches()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(patches) != 2 {
+		t.Fatalf("expected dev-slot lifecycle to be added, got %d patches", len(patches))
+	}
+	if p, ok := patches[0].(cfnpatch.DevSlotLifecycle); !ok || p.ExpirationDays != cfnpatch.DefaultDevSlotExpirationDays {
+		t.Errorf("first patch = %#v, want default dev-slot lifecycle", patches[0])
+	}
+
+	cfg = cdkConfig{BootstrapPatches: []patchConfig{
+		{Type: patchTags, Tags: map[string]string{"team": "platform"}},
+		{Type: patchDevSlotLifecycle, Days: 3},
+	}}
+	patches, err = cfg.bootstrapPatches()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(patches) != 2 || patches[1].(cfnpatch.DevSlotLifecycle).ExpirationDays != 3 {
+		t.Errorf("configured order and days should be kept, got %#v", patches)
+	}
+
+	for _, bad := range []patchConfig{
+		{Type: "unknown"},
+		{Type: patchBucketAccessLogging},
+		{Type: patchTags},
+		{Type: patchECRLifecycle, Policy: "not json"},
+		{Type: patchDevSlotLifecycle, Days: -1},
+	} {
+		cfg := cdkConfig{BootstrapPatches: []patchConfig{bad}}
+		if _, err := cfg.bootstrapPatches(); err == nil {
+			t.Errorf("expected error for %+v", bad)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package cdktool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/basewarphq/bw/cmd/internal/cdkctx"
+	"github.com/basewarphq/bw/cmd/internal/cfndeploy"
+	"github.com/basewarphq/bw/cmd/internal/cfnread"
+	"github.com/basewarphq/bw/cmd/internal/cmdexec"
+	"github.com/basewarphq/bw/cmd/internal/devslot"
+	"github.com/basewarphq/bw/cmd/internal/gitinfo"
+	"github.com/basewarphq/bw/cmd/internal/tool"
+	"github.com/cockroachdb/errors"
+)
+
+const stackPollInterval = 5 * time.Second
+
+// deployPlan is the reviewable artifact written by `deploy --plan` and
+// executed by `deploy --apply`. It pins change sets by ARN, so apply runs
+// exactly what was reviewed.
+type deployPlan struct {
+	Deployment string             `json:"deployment"`
+	Revision   string             `json:"revision,omitempty"`
+	CreatedAt  time.Time          `json:"created_at"`
+	ChangeSets []plannedChangeSet `json:"change_sets"`
+}
+
+type plannedChangeSet struct {
+	Stack       string    `json:"stack"`
+	Region      string    `json:"region"`
+	ChangeSetID string    `json:"change_set_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (t *Tool) plan(
+	ctx context.Context, dir string, cfg *cdkConfig, cctx *cdkctx.CDKContext,
+	stacks appStacks, deployment string, opts tool.DeployOptions,
+) error {
+	rev := gitinfo.Revision(ctx, dir)
+	name := changeSetPrefix + rev
+	if rev == "" {
+		name = changeSetPrefix + "plan-" + strconv.FormatInt(time.Now().Unix(), 10)
+	}
+
+	args := []string{
+		"deploy", "--no-execute",
+		"--require-approval", "never",
+		"--change-set-name", name,
+	}
+	args = append(args, cfg.cdkArgs(cctx.Qualifier)...)
+	args = append(args, stacks.cdkArgs(stacks.All())...)
+	if err := cmdexec.Run(ctx, dir, "cdk", args...); err != nil {
+		return err
+	}
+
+	plan := deployPlan{Deployment: deployment, Revision: rev, CreatedAt: time.Now().UTC()}
+	for _, stack := range stacks.All() {
+		cs, err := cfnread.DescribeChangeSet(ctx, stack.Region, cfg.Profile, stack.Name, name)
+		if errors.Is(err, cfnread.ErrChangeSetNotFound) {
+			// cdk deletes change sets without changes.
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if emptyChangeSet(cs) {
+			// With --no-execute cdk keeps change sets without changes around
+			// as FAILED; they would only block the next plan.
+			if err := cfndeploy.DeleteChangeSet(ctx, stack.Region, cfg.Profile, cs.ID); err != nil {
+				return errors.Wrapf(err, "deleting empty change set for %s", stack.Name)
+			}
+			continue
+		}
+		if cs.Status != "CREATE_COMPLETE" {
+			return errors.Newf("change set for %s is %s: %s", stack.Name, cs.Status, cs.StatusReason)
+		}
+		plan.ChangeSets = append(plan.ChangeSets, plannedChangeSet{
+			Stack:       stack.Name,
+			Region:      stack.Region,
+			ChangeSetID: cs.ID,
+			CreatedAt:   cs.CreationTime,
+		})
+	}
+
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "encoding plan")
+	}
+	if err := os.WriteFile(opts.PlanFile, append(data, '\n'), 0o600); err != nil {
+		return errors.Wrap(err, "writing plan file")
+	}
+	fmt.Fprintf(os.Stderr, "Wrote plan with %d change set(s) to %s\n", len(plan.ChangeSets), opts.PlanFile)
+	return nil
+}
+
+// emptyChangeSet reports whether CloudFormation refused to create a change
+// set because there was nothing to change.
+func emptyChangeSet(cs *cfnread.ChangeSet) bool {
+	return cs.Status == "FAILED" &&
+		(strings.Contains(cs.StatusReason, "didn't contain changes") ||
+			strings.Contains(cs.StatusReason, "No updates are to be performed"))
+}
+
+func readPlan(path string) (*deployPlan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading plan file")
+	}
+	var plan deployPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, errors.Wrapf(err, "parsing plan file %s", path)
+	}
+	if plan.Deployment == "" {
+		return nil, errors.Newf("plan file %s has no deployment", path)
+	}
+	return &plan, nil
+}
+
+func (t *Tool) apply(ctx context.Context, cfg *cdkConfig, plan *deployPlan) error {
+	// Check every stack before executing anything, so a stale plan never
+	// rolls out halfway.
+	for _, pcs := range plan.ChangeSets {
+		if err := checkPlannedChangeSet(ctx, cfg.Profile, pcs); err != nil {
+			return err
+		}
+	}
+
+	for _, pcs := range plan.ChangeSets {
+		fmt.Fprintf(os.Stderr, "Executing change set for %s (%s)...\n", pcs.Stack, pcs.Region)
+		if err := cfndeploy.ExecuteChangeSet(ctx, pcs.Region, cfg.Profile, pcs.ChangeSetID); err != nil {
+			return errors.Wrapf(err, "executing change set for %s", pcs.Stack)
+		}
+		if err := waitForChangeSet(ctx, cfg.Profile, pcs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkPlannedChangeSet(ctx context.Context, profile string, pcs plannedChangeSet) error {
+	cs, err := cfnread.DescribeChangeSet(ctx, pcs.Region, profile, "", pcs.ChangeSetID)
+	if err != nil {
+		return errors.Wrapf(err, "plan for %s", pcs.Stack)
+	}
+	if cs.ExecutionStatus != "AVAILABLE" {
+		return errors.Newf("change set for %s can no longer be executed (%s)", pcs.Stack, cs.ExecutionStatus)
+	}
+
+	others, err := cfnread.ListChangeSets(ctx, pcs.Region, profile, pcs.Stack)
+	if err != nil {
+		return err
+	}
+	if newer := newerChangeSets(others, cs); len(newer) > 0 {
+		return errors.Newf("refusing to apply plan: %s has newer change set(s) %s",
+			pcs.Stack, strings.Join(newer, ", "))
+	}
+
+	stack, err := cfnread.DescribeStack(ctx, pcs.Region, profile, pcs.Stack)
+	if err != nil {
+		return err
+	}
+	if stack.Status == "REVIEW_IN_PROGRESS" {
+		// The stack is created by this change set; there is nothing to drift.
+		return nil
+	}
+	drift, err := cfnread.DetectStackDrift(ctx, pcs.Region, profile, pcs.Stack)
+	if err != nil {
+		return err
+	}
+	if drift == "DRIFTED" {
+		return errors.Newf("refusing to apply plan: %s has drifted since it was deployed", pcs.Stack)
+	}
+	return nil
+}
+
+func newerChangeSets(all []cfnread.ChangeSet, planned *cfnread.ChangeSet) []string {
+	var newer []string
+	for _, cs := range all {
+		if cs.ID != planned.ID && cs.CreationTime.After(planned.CreationTime) {
+			newer = append(newer, cs.Name)
+		}
+	}
+	return newer
+}
+
+// waitForChangeSet blocks until the executed change set has finished and
+// the stack has settled. Polling the change set rather than the stack
+// alone avoids reading the stack's status from before the execution began.
+func waitForChangeSet(ctx context.Context, profile string, pcs plannedChangeSet) error {
+	for {
+		cs, err := cfnread.DescribeChangeSet(ctx, pcs.Region, profile, "", pcs.ChangeSetID)
+		if err != nil {
+			return err
+		}
+		if cs.ExecutionStatus == "EXECUTE_COMPLETE" || cs.ExecutionStatus == "EXECUTE_FAILED" {
+			stack, err := cfnread.DescribeStack(ctx, pcs.Region, profile, pcs.Stack)
+			if err != nil {
+				return err
+			}
+			if done, err := stackSettled(pcs.Stack, stack.Status); done {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(stackPollInterval):
+		}
+	}
+}
+
+// stackSettled reports whether a stack status is terminal, and if so
+// whether the update behind it failed.
+func stackSettled(name, status string) (bool, error) {
+	switch status {
+	case "CREATE_COMPLETE", "UPDATE_COMPLETE", "IMPORT_COMPLETE":
+		return true, nil
+	case "CREATE_FAILED", "ROLLBACK_COMPLETE", "ROLLBACK_FAILED",
+		"UPDATE_FAILED", "UPDATE_ROLLBACK_COMPLETE", "UPDATE_ROLLBACK_FAILED",
+		"IMPORT_ROLLBACK_COMPLETE", "IMPORT_ROLLBACK_FAILED",
+		"DELETE_COMPLETE", "DELETE_FAILED":
+		return true, errors.Newf("%s ended in %s", name, status)
+	default:
+		return false, nil
+	}
+}
+
+func (t *Tool) applyPlanFile(ctx context.Context, dir string, cfg *cdkConfig, opts tool.DeployOptions) error {
+	plan, err := readPlan(opts.ApplyFile)
+	if err != nil {
+		return err
+	}
+	if d, ok := tool.DeploymentFrom(ctx); ok && d != "" && d != plan.Deployment {
+		return errors.Newf("plan is for deployment %s, not %s", plan.Deployment, d)
+	}
+	cctx, err := cdkctx.Load(dir)
+	if err != nil {
+		return err
+	}
+	cfg = cfg.forDeployment(cctx, plan.Deployment)
+	if err := cfg.checkPolicies(ctx, dir, plan.Deployment, opts); err != nil {
+		return err
+	}
+
+	applyErr := t.apply(ctx, cfg, plan)
+	devslot.RecordDeploy(ctx, dir, cfg.slotSettings(), plan.Deployment, applyErr)
+	return applyErr
+}
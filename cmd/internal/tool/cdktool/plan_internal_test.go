@@ -0,0 +1,101 @@
+package cdktool
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/basewarphq/bw/cmd/internal/cfnread"
+)
+
+func TestNewerChangeSets(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	planned := &cfnread.ChangeSet{ID: "arn:planned", Name: "bw-abc", CreationTime: base}
+	all := []cfnread.ChangeSet{
+		*planned,
+		{ID: "arn:older", Name: "bw-old", CreationTime: base.Add(-time.Hour)},
+		{ID: "arn:newer", Name: "bw-new", CreationTime: base.Add(time.Minute)},
+	}
+
+	newer := newerChangeSets(all, planned)
+	if len(newer) != 1 || newer[0] != "bw-new" {
+		t.Errorf("got %v, want [bw-new]", newer)
+	}
+	if newer := newerChangeSets(all[:2], planned); len(newer) != 0 {
+		t.Errorf("got %v, want none", newer)
+	}
+}
+
+func TestReadPlan(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	good := filepath.Join(dir, "plan.json")
+	if err := os.WriteFile(good, []byte(`{
+  "deployment": "Prod",
+  "change_sets": [{"stack": "bwappEucProd", "region": "eu-central-1", "change_set_id": "arn:cs"}]
+}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	plan, err := readPlan(good)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Deployment != "Prod" || len(plan.ChangeSets) != 1 || plan.ChangeSets[0].ChangeSetID != "arn:cs" {
+		t.Errorf("got %+v", plan)
+	}
+
+	empty := filepath.Join(dir, "empty.json")
+	if err := os.WriteFile(empty, []byte(`{}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readPlan(empty); err == nil {
+		t.Error("expected error for plan without deployment")
+	}
+}
+
+func TestEmptyChangeSet(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		cs   cfnread.ChangeSet
+		want bool
+	}{
+		{cfnread.ChangeSet{Status: "CREATE_COMPLETE"}, false},
+		{cfnread.ChangeSet{
+			Status:       "FAILED",
+			StatusReason: "The submitted information didn't contain changes. Submit different information to create a change set.",
+		}, true},
+		{cfnread.ChangeSet{Status: "FAILED", StatusReason: "No updates are to be performed."}, true},
+		{cfnread.ChangeSet{Status: "FAILED", StatusReason: "Template format error"}, false},
+	} {
+		if got := emptyChangeSet(&tc.cs); got != tc.want {
+			t.Errorf("%+v: got %v, want %v", tc.cs, got, tc.want)
+		}
+	}
+}
+
+func TestStackSettled(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		status     string
+		done, fail bool
+	}{
+		{"UPDATE_IN_PROGRESS", false, false},
+		{"UPDATE_COMPLETE_CLEANUP_IN_PROGRESS", false, false},
+		{"UPDATE_COMPLETE", true, false},
+		{"CREATE_COMPLETE", true, false},
+		{"UPDATE_ROLLBACK_COMPLETE", true, true},
+		{"ROLLBACK_COMPLETE", true, true},
+		{"CREATE_FAILED", true, true},
+	} {
+		done, err := stackSettled("Stack", tc.status)
+		if done != tc.done || (err != nil) != tc.fail {
+			t.Errorf("%s: got %v, %v", tc.status, done, err)
+		}
+	}
+}
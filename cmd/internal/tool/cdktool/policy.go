@@ -0,0 +1,189 @@
+package cdktool
+
+import (
+	"context"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/basewarphq/bw/cmd/internal/devslot"
+	"github.com/basewarphq/bw/cmd/internal/gitinfo"
+	"github.com/basewarphq/bw/cmd/internal/tool"
+	"github.com/cockroachdb/errors"
+)
+
+// Approval levels accepted by cdk's --require-approval, from least to most
+// strict.
+const (
+	approvalNever      = "never"
+	approvalBroadening = "broadening"
+	approvalAnyChange  = "any-change"
+)
+
+var approvalLevels = []string{approvalNever, approvalBroadening, approvalAnyChange}
+
+// deployPolicy guards deploys of the deployments matching its key in
+// [project.tool.cdk.policy]. Keys are deployment names or path.Match
+// patterns; every matching policy must be satisfied.
+type deployPolicy struct {
+	RequireCleanTree bool     `toml:"require-clean-tree"`
+	Refs             []string `toml:"refs"`
+	RequireApproval  string   `toml:"require-approval"`
+	ForbidHotswap    bool     `toml:"forbid-hotswap"`
+	Profiles         []string `toml:"profiles"`
+	Accounts         []string `toml:"accounts"`
+}
+
+func validatePolicies(policies map[string]deployPolicy) error {
+	for pattern, p := range policies {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return errors.Newf("policy %q: invalid deployment pattern", pattern)
+		}
+		if p.RequireApproval != "" && !slices.Contains(approvalLevels, p.RequireApproval) {
+			return errors.Newf("policy %q: require-approval must be one of %s, got %q",
+				pattern, strings.Join(approvalLevels, ", "), p.RequireApproval)
+		}
+		for _, ref := range p.Refs {
+			if _, err := path.Match(ref, ""); err != nil {
+				return errors.Newf("policy %q: invalid ref pattern %q", pattern, ref)
+			}
+		}
+	}
+	return nil
+}
+
+// policiesFor returns the patterns of the policies that apply to deployment,
+// sorted for stable reporting.
+func (c *cdkConfig) policiesFor(deployment string) []string {
+	var patterns []string
+	for pattern := range c.Policies {
+		if ok, _ := path.Match(pattern, deployment); ok {
+			patterns = append(patterns, pattern)
+		}
+	}
+	sort.Strings(patterns)
+	return patterns
+}
+
+// approvalFor returns the strictest require-approval level of the policies
+// that apply to deployment, defaulting to never.
+func (c *cdkConfig) approvalFor(deployment string) string {
+	level := 0
+	for _, pattern := range c.policiesFor(deployment) {
+		if i := slices.Index(approvalLevels, c.Policies[pattern].RequireApproval); i > level {
+			level = i
+		}
+	}
+	return approvalLevels[level]
+}
+
+// policyFacts describes the deploy being attempted. Only the facts some
+// applicable policy asks about are gathered.
+type policyFacts struct {
+	Dirty   bool
+	Branch  string
+	Tags    []string
+	Profile string
+	Account string
+	Hotswap bool
+}
+
+func (c *cdkConfig) checkPolicies(ctx context.Context, dir, deployment string, opts tool.DeployOptions) error {
+	patterns := c.policiesFor(deployment)
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	facts := policyFacts{Profile: c.Profile, Hotswap: opts.Hotswap}
+	var needClean, needRefs, needAccount bool
+	for _, pattern := range patterns {
+		p := c.Policies[pattern]
+		needClean = needClean || p.RequireCleanTree
+		needRefs = needRefs || len(p.Refs) > 0
+		needAccount = needAccount || len(p.Accounts) > 0
+	}
+
+	if needClean {
+		dirty, err := gitinfo.IsDirty(ctx, dir)
+		if err != nil {
+			return errors.Wrap(err, "checking deploy policy")
+		}
+		facts.Dirty = dirty
+	}
+	if needRefs {
+		// A detached HEAD has no branch but may still carry a matching tag.
+		facts.Branch, _ = gitinfo.Branch(ctx, dir)
+		tags, err := gitinfo.Tags(ctx, dir)
+		if err != nil {
+			return errors.Wrap(err, "checking deploy policy")
+		}
+		facts.Tags = tags
+	}
+	if needAccount {
+		account, err := devslot.AccountID(ctx, c.Profile)
+		if err != nil {
+			return errors.Wrap(err, "checking deploy policy")
+		}
+		facts.Account = account
+	}
+
+	var violations []string
+	for _, pattern := range patterns {
+		for _, v := range policyViolations(c.Policies[pattern], facts) {
+			violations = append(violations, v+" (policy "+pattern+")")
+		}
+	}
+	if len(violations) > 0 {
+		return errors.Newf("deploy of %s blocked by policy:\n  - %s",
+			deployment, strings.Join(violations, "\n  - "))
+	}
+	return nil
+}
+
+func policyViolations(p deployPolicy, facts policyFacts) []string {
+	var violations []string
+	if p.RequireCleanTree && facts.Dirty {
+		violations = append(violations, "git working tree has uncommitted changes")
+	}
+	if len(p.Refs) > 0 && !refAllowed(p.Refs, facts.Branch, facts.Tags) {
+		at := "detached HEAD"
+		if facts.Branch != "" {
+			at = "branch " + facts.Branch
+		}
+		violations = append(violations, "HEAD ("+at+") is not on an allowed branch or tag: "+
+			strings.Join(p.Refs, ", "))
+	}
+	if p.ForbidHotswap && facts.Hotswap {
+		violations = append(violations, "hotswap deploys are not allowed")
+	}
+	if len(p.Profiles) > 0 && !slices.Contains(p.Profiles, facts.Profile) {
+		profile := facts.Profile
+		if profile == "" {
+			profile = "(default)"
+		}
+		violations = append(violations, "AWS profile "+profile+" is not allowed: "+
+			strings.Join(p.Profiles, ", "))
+	}
+	if len(p.Accounts) > 0 && !slices.Contains(p.Accounts, facts.Account) {
+		violations = append(violations, "AWS account "+facts.Account+" is not allowed: "+
+			strings.Join(p.Accounts, ", "))
+	}
+	return violations
+}
+
+func refAllowed(patterns []string, branch string, tags []string) bool {
+	for _, pattern := range patterns {
+		if branch != "" {
+			if ok, _ := path.Match(pattern, branch); ok {
+				return true
+			}
+		}
+		for _, tag := range tags {
+			if ok, _ := path.Match(pattern, tag); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
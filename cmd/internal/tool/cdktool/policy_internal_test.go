@@ -0,0 +1,75 @@
+package cdktool
+
+import (
+	"testing"
+)
+
+func TestPolicyViolations(t *testing.T) {
+	t.Parallel()
+
+	strict := deployPolicy{
+		RequireCleanTree: true,
+		Refs:             []string{"main", "v*"},
+		ForbidHotswap:    true,
+		Profiles:         []string{"prod-admin"},
+		Accounts:         []string{"111111111111"},
+	}
+
+	ok := policyFacts{Branch: "main", Profile: "prod-admin", Account: "111111111111"}
+	if v := policyViolations(strict, ok); len(v) != 0 {
+		t.Errorf("expected no violations, got %v", v)
+	}
+
+	tagged := policyFacts{Tags: []string{"v1.2.0"}, Profile: "prod-admin", Account: "111111111111"}
+	if v := policyViolations(strict, tagged); len(v) != 0 {
+		t.Errorf("detached HEAD at an allowed tag should pass, got %v", v)
+	}
+
+	bad := policyFacts{Dirty: true, Branch: "feature", Profile: "dev", Account: "222222222222", Hotswap: true}
+	if v := policyViolations(strict, bad); len(v) != 5 {
+		t.Errorf("expected 5 violations, got %d: %v", len(v), v)
+	}
+
+	if v := policyViolations(deployPolicy{}, bad); len(v) != 0 {
+		t.Errorf("empty policy should allow everything, got %v", v)
+	}
+}
+
+func TestApprovalFor(t *testing.T) {
+	t.Parallel()
+
+	cfg := cdkConfig{Policies: map[string]deployPolicy{
+		"Prod": {RequireApproval: approvalBroadening},
+		"Pr*":  {RequireApproval: approvalAnyChange},
+		"Stag": {RequireCleanTree: true},
+		"Dev*": {},
+	}}
+
+	for deployment, want := range map[string]string{
+		"Prod":  approvalAnyChange,
+		"Stag":  approvalNever,
+		"Dev03": approvalNever,
+		"Other": approvalNever,
+	} {
+		if got := cfg.approvalFor(deployment); got != want {
+			t.Errorf("approvalFor(%q) = %q, want %q", deployment, got, want)
+		}
+	}
+}
+
+func TestValidatePolicies(t *testing.T) {
+	t.Parallel()
+
+	if err := validatePolicies(map[string]deployPolicy{"Prod": {RequireApproval: approvalBroadening}}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := validatePolicies(map[string]deployPolicy{"Prod": {RequireApproval: "always"}}); err == nil {
+		t.Error("expected error for unknown approval level")
+	}
+	if err := validatePolicies(map[string]deployPolicy{"[": {}}); err == nil {
+		t.Error("expected error for bad deployment pattern")
+	}
+	if err := validatePolicies(map[string]deployPolicy{"Prod": {Refs: []string{"["}}}); err == nil {
+		t.Error("expected error for bad ref pattern")
+	}
+}
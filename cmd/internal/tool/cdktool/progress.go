@@ -0,0 +1,123 @@
+package cdktool
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/basewarphq/bw/cmd/internal/cdkmanifest"
+	"github.com/basewarphq/bw/cmd/internal/cfnprogress"
+	"github.com/basewarphq/bw/cmd/internal/cfnread"
+	"github.com/basewarphq/bw/cmd/internal/cmdexec"
+	"github.com/basewarphq/bw/cmd/internal/parallel"
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	progressPollInterval = 3 * time.Second
+	progressConcurrency  = 8
+	// logTailLines of the cdk log are shown when a deploy fails, since
+	// synth and asset errors never reach CloudFormation events.
+	logTailLines = 20
+)
+
+func stderrIsTerminal() bool {
+	fi, err := os.Stderr.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// deployWithProgress runs cdk deploy with its output sent to a log file and
+// shows a live table of the CloudFormation events of every stack instead.
+func deployWithProgress(
+	ctx context.Context, dir string, cfg *cdkConfig, app []*cdkmanifest.Stack, args []string,
+) error {
+	stacks := make([]*cfnprogress.Stack, len(app))
+	parallel.ForEach(len(app), progressConcurrency, func(i int) {
+		stacks[i] = &cfnprogress.Stack{
+			Name:              app[i].Name,
+			Region:            app[i].Region,
+			TemplateResources: app[i].ResourceCount,
+		}
+		events, err := cfnread.StackEvents(ctx, app[i].Region, cfg.Profile, app[i].Name)
+		if err == nil && len(events) > 0 {
+			stacks[i].Baseline = events[0].ID
+		}
+		stacks[i].Update(nil)
+	})
+
+	logFile, err := os.CreateTemp("", "bw-cdk-deploy-*.log")
+	if err != nil {
+		return errors.Wrap(err, "creating cdk log file")
+	}
+	defer logFile.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmdexec.RunTo(ctx, dir, logFile, "cdk", args...)
+	}()
+
+	live := &cfnprogress.Live{W: os.Stderr}
+	ticker := time.NewTicker(progressPollInterval)
+	defer ticker.Stop()
+	var deployErr error
+loop:
+	for {
+		_ = live.Redraw(stacks, time.Now())
+		select {
+		case deployErr = <-done:
+			break loop
+		case <-ticker.C:
+			pollProgress(ctx, cfg, stacks)
+		}
+	}
+	pollProgress(ctx, cfg, stacks)
+	_ = live.Redraw(stacks, time.Now())
+
+	if deployErr == nil {
+		os.Remove(logFile.Name())
+		return nil
+	}
+
+	for _, s := range stacks {
+		if line := cfnprogress.FailureLine(s); line != "" {
+			fmt.Fprintf(os.Stderr, "First failure in %s\n", line)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "\nLast lines of the cdk output (full log: %s):\n", logFile.Name())
+	for _, line := range tailLines(logFile.Name(), logTailLines) {
+		fmt.Fprintln(os.Stderr, "  "+line)
+	}
+	return errors.Newf("cdk deploy failed (full log: %s)", logFile.Name())
+}
+
+func pollProgress(ctx context.Context, cfg *cdkConfig, stacks []*cfnprogress.Stack) {
+	parallel.ForEach(len(stacks), progressConcurrency, func(i int) {
+		s := stacks[i]
+		events, err := cfnread.StackEvents(ctx, s.Region, cfg.Profile, s.Name)
+		if err != nil {
+			// Keep the last known state; the next poll will likely succeed.
+			return
+		}
+		s.Update(events)
+	})
+}
+
+func tailLines(path string, n int) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var lines []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+		if len(lines) > n {
+			lines = lines[1:]
+		}
+	}
+	return lines
+}
@@ -0,0 +1,155 @@
+package cdktool
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/basewarphq/bw/cmd/internal/cdkctx"
+	"github.com/basewarphq/bw/cmd/internal/cdkmanifest"
+	"github.com/basewarphq/bw/cmd/internal/cmdexec"
+	"github.com/cockroachdb/errors"
+)
+
+// A bw process runs one command, so assemblies synthesized by earlier steps
+// or lenses are reused for the rest of it.
+var (
+	synthMu     sync.Mutex
+	synthesized = map[string]*cdkmanifest.Assembly{}
+)
+
+// synth synthesizes the app into its output directory at most once per
+// command and reads the resulting cloud assembly. Commands that follow pass
+// the assembly to cdk with --app so it is not synthesized again.
+func synth(ctx context.Context, cfg *cdkConfig, cctx *cdkctx.CDKContext, dir string) (*cdkmanifest.Assembly, error) {
+	synthMu.Lock()
+	defer synthMu.Unlock()
+	if asm, ok := synthesized[dir]; ok {
+		return asm, nil
+	}
+
+	outDir, err := assemblyDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	args := []string{"synth", "--quiet", "--output", outDir}
+	args = append(args, cfg.cdkArgs(cctx.Qualifier)...)
+	if _, err := cmdexec.Output(ctx, dir, "cdk", args...); err != nil {
+		return nil, errors.Wrap(err, "synthesizing CDK app")
+	}
+
+	asm, err := cdkmanifest.Load(outDir)
+	if err != nil {
+		return nil, err
+	}
+	synthesized[dir] = asm
+	return asm, nil
+}
+
+// assemblyDir is the output directory configured in cdk.json, cdk.out by
+// default.
+func assemblyDir(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "cdk.json"))
+	if err != nil {
+		return "", errors.Wrap(err, "reading cdk.json")
+	}
+	var cdkJSON struct {
+		Output string `json:"output"`
+	}
+	if err := json.Unmarshal(data, &cdkJSON); err != nil {
+		return "", errors.Wrap(err, "parsing cdk.json")
+	}
+	if cdkJSON.Output == "" {
+		cdkJSON.Output = "cdk.out"
+	}
+	if filepath.IsAbs(cdkJSON.Output) {
+		return cdkJSON.Output, nil
+	}
+	return filepath.Join(dir, cdkJSON.Output), nil
+}
+
+// appStacks are the stacks of one deployment in the cloud assembly, each in
+// deploy order.
+type appStacks struct {
+	asm        *cdkmanifest.Assembly
+	Shared     []*cdkmanifest.Stack
+	Deployment []*cdkmanifest.Stack
+}
+
+// All returns the shared stacks followed by the deployment stacks.
+func (s appStacks) All() []*cdkmanifest.Stack {
+	return append(append([]*cdkmanifest.Stack{}, s.Shared...), s.Deployment...)
+}
+
+// Refs returns All as stack references.
+func (s appStacks) Refs() []cdkctx.StackRef {
+	return stackRefs(s.All())
+}
+
+// cdkArgs points cdk at the synthesized assembly and selects the stacks by
+// artifact ID.
+func (s appStacks) cdkArgs(stacks []*cdkmanifest.Stack) []string {
+	args := []string{"--app", s.asm.Dir}
+	for _, st := range stacks {
+		args = append(args, st.ID)
+	}
+	return args
+}
+
+func stackRefs(stacks []*cdkmanifest.Stack) []cdkctx.StackRef {
+	refs := make([]cdkctx.StackRef, len(stacks))
+	for i, st := range stacks {
+		refs[i] = cdkctx.StackRef{Name: st.Name, Region: st.Region}
+	}
+	return refs
+}
+
+// deploymentStacks synthesizes the app and picks the shared stacks and the
+// stacks of deployment out of the assembly. Regions come from the stack
+// environments; only environment-agnostic stacks fall back to the region in
+// their name.
+func deploymentStacks(
+	ctx context.Context, cfg *cdkConfig, cctx *cdkctx.CDKContext, dir, deployment string,
+) (appStacks, error) {
+	asm, err := synth(ctx, cfg, cctx, dir)
+	if err != nil {
+		return appStacks{}, err
+	}
+	return selectStacks(asm, cctx, deployment)
+}
+
+func selectStacks(asm *cdkmanifest.Assembly, cctx *cdkctx.CDKContext, deployment string) (appStacks, error) {
+	ordered, err := asm.DeployOrder()
+	if err != nil {
+		return appStacks{}, err
+	}
+
+	account := cctx.DeploymentAccount(deployment)
+	stacks := appStacks{asm: asm}
+	for _, st := range ordered {
+		sn, ok := cctx.ParseStackName(st.Name)
+		if !ok || !sn.Shared && sn.Deployment != deployment {
+			continue
+		}
+		if sn.Shared && account != "" && st.Account != "" && st.Account != account {
+			// Multi-account apps have shared stacks in every account; only
+			// those of the deployment's account belong to it.
+			continue
+		}
+		if st.Region == "" {
+			// Environment-agnostic stacks still carry their region in the name.
+			st.Region = sn.Region
+		}
+		if sn.Shared {
+			stacks.Shared = append(stacks.Shared, st)
+		} else {
+			stacks.Deployment = append(stacks.Deployment, st)
+		}
+	}
+	if len(stacks.Deployment) == 0 {
+		return appStacks{}, errors.Newf("the CDK app has no stacks for deployment %q", deployment)
+	}
+	return stacks, nil
+}
@@ -0,0 +1,151 @@
+package cdktool
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/basewarphq/bw/cmd/internal/cdkctx"
+	"github.com/basewarphq/bw/cmd/internal/cdkmanifest"
+	"github.com/basewarphq/bw/cmd/internal/testutil"
+)
+
+func TestSelectStacks(t *testing.T) {
+	t.Parallel()
+
+	stack := func(id, region string, deps ...string) string {
+		quoted := make([]string, len(deps))
+		for i, d := range deps {
+			quoted[i] = `"` + d + `"`
+		}
+		return fmt.Sprintf(`"%s": {"type": "aws:cloudformation:stack", "environment": "aws://unknown-account/%s",
+			"properties": {"templateFile": "%s.template.json"}, "dependencies": [%s]}`,
+			id, region, id, strings.Join(quoted, ","))
+	}
+	files := map[string]string{
+		"cdk.json": `{"context": {"@aws-cdk/core:bootstrapQualifier": "bwapp"}}`,
+		"cdk.context.json": `{
+			"bwapp-primary-region": "eu-central-1",
+			"bwapp-secondary-regions": ["eu-west-1"],
+			"bwapp-deployments": ["Prod", "Dev01"]
+		}`,
+		"cdk.out/manifest.json": `{"artifacts": {` + strings.Join([]string{
+			stack("bwappEuw1Prod", "eu-west-1", "bwappEuc1Prod", "bwappEuw1Shared"),
+			stack("bwappEuc1Prod", "eu-central-1", "bwappEuc1Shared"),
+			stack("bwappEuw1Shared", "unknown-region", "bwappEuc1Shared"),
+			stack("bwappEuc1Shared", "eu-central-1"),
+			stack("bwappEuc1Dev01", "eu-central-1", "bwappEuc1Shared"),
+			stack("bwappEuc1Stag", "eu-central-1", "bwappEuc1Shared"),
+		}, ",") + `}}`,
+	}
+	for _, id := range []string{"bwappEuw1Prod", "bwappEuc1Prod", "bwappEuw1Shared", "bwappEuc1Shared", "bwappEuc1Dev01", "bwappEuc1Stag"} {
+		files["cdk.out/"+id+".template.json"] = `{"Resources": {}}`
+	}
+	dir := testutil.Setup(t, files)
+
+	cctx, err := cdkctx.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asm, err := cdkmanifest.Load(dir + "/cdk.out")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app, err := selectStacks(asm, cctx, "Prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"bwappEuc1Shared/eu-central-1", "bwappEuw1Shared/eu-west-1",
+		"bwappEuc1Prod/eu-central-1", "bwappEuw1Prod/eu-west-1"}
+	var got []string
+	for _, ref := range app.Refs() {
+		got = append(got, ref.Name+"/"+ref.Region)
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	destroy := destroyOrder(app)
+	if len(destroy) != 2 || destroy[0].Name != "bwappEuw1Prod" || destroy[1].Name != "bwappEuc1Prod" {
+		t.Errorf("destroy order: got %v", stackRefs(destroy))
+	}
+	if args := app.cdkArgs(destroy); args[0] != "--app" || args[1] != dir+"/cdk.out" || args[2] != "bwappEuw1Prod" {
+		t.Errorf("cdk args: got %v", args)
+	}
+
+	if _, err := selectStacks(asm, cctx, "Dev02"); err == nil {
+		t.Error("expected error for a deployment without stacks")
+	}
+}
+
+func TestSelectStacks_MultiAccount(t *testing.T) {
+	t.Parallel()
+
+	stack := func(id, account string, deps ...string) string {
+		quoted := make([]string, len(deps))
+		for i, d := range deps {
+			quoted[i] = `"` + d + `"`
+		}
+		name := strings.TrimSuffix(strings.TrimSuffix(id, "111111111111"), "222222222222")
+		return fmt.Sprintf(`"%s": {"type": "aws:cloudformation:stack", "environment": "aws://%s/eu-central-1",
+			"properties": {"templateFile": "%s.template.json", "stackName": "%s"}, "dependencies": [%s]}`,
+			id, account, id, name, strings.Join(quoted, ","))
+	}
+	ids := []string{"bwappEuc1Shared111111111111", "bwappEuc1Shared222222222222", "bwappEuc1Prod", "bwappEuc1Dev01"}
+	files := map[string]string{
+		"cdk.json": `{"context": {"@aws-cdk/core:bootstrapQualifier": "bwapp"}}`,
+		"cdk.context.json": `{
+			"bwapp-primary-region": "eu-central-1",
+			"bwapp-deployments": ["Prod", "Dev01"],
+			"bwapp-deployment-accounts": {"Prod": "111111111111", "Dev*": "222222222222"}
+		}`,
+		"cdk.out/manifest.json": `{"artifacts": {` + strings.Join([]string{
+			stack(ids[0], "111111111111"),
+			stack(ids[1], "222222222222"),
+			stack(ids[2], "111111111111", ids[0]),
+			stack(ids[3], "222222222222", ids[1]),
+		}, ",") + `}}`,
+	}
+	for _, id := range ids {
+		files["cdk.out/"+id+".template.json"] = `{"Resources": {}}`
+	}
+	dir := testutil.Setup(t, files)
+
+	cctx, err := cdkctx.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asm, err := cdkmanifest.Load(dir + "/cdk.out")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for deployment, want := range map[string]string{
+		"Prod":  "bwappEuc1Shared111111111111 bwappEuc1Prod",
+		"Dev01": "bwappEuc1Shared222222222222 bwappEuc1Dev01",
+	} {
+		app, err := selectStacks(asm, cctx, deployment)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, st := range app.All() {
+			got = append(got, st.ID)
+		}
+		if strings.Join(got, " ") != want {
+			t.Errorf("%s: got %v, want %s", deployment, got, want)
+		}
+	}
+
+	cfg := &cdkConfig{Profile: "default", AccountProfiles: map[string]string{"111111111111": "prod"}}
+	if got := cfg.forDeployment(cctx, "Prod").Profile; got != "prod" {
+		t.Errorf("Prod profile: got %q, want prod", got)
+	}
+	if got := cfg.forDeployment(cctx, "Dev01").Profile; got != "default" {
+		t.Errorf("Dev01 profile: got %q, want default", got)
+	}
+	if cfg.Profile != "default" {
+		t.Errorf("forDeployment changed the receiver: %q", cfg.Profile)
+	}
+}
@@ -9,6 +9,9 @@ type Inspection struct {
 	Name        string
 	Description string
 	Run         func(ctx context.Context, dir string, r NodeReporter) error
+	// OptIn lenses only run when selected by name, because they are slow or
+	// have effects beyond reading state, like starting drift detection.
+	OptIn bool
 }
 
 type InspectionProvider interface {
@@ -21,6 +24,9 @@ func RunInspections(ctx context.Context, p InspectionProvider, dir string, r Nod
 		if len(selected) > 0 && !slices.Contains(selected, insp.Name) {
 			continue
 		}
+		if len(selected) == 0 && insp.OptIn {
+			continue
+		}
 		r.Section(insp.Name)
 		if err := insp.Run(ctx, dir, r); err != nil {
 			return err
@@ -52,3 +58,19 @@ func InspectSelectionFrom(ctx context.Context) []string {
 	v, _ := ctx.Value(inspectSelectionKey{}).([]string)
 	return v
 }
+
+type InspectOptions struct {
+	// Strict makes lenses fail when they find a problem, such as drift.
+	Strict bool
+}
+
+type inspectOptionsKey struct{}
+
+func WithInspectOptions(ctx context.Context, opts InspectOptions) context.Context {
+	return context.WithValue(ctx, inspectOptionsKey{}, opts)
+}
+
+func InspectOptionsFrom(ctx context.Context) (InspectOptions, bool) {
+	opts, ok := ctx.Value(inspectOptionsKey{}).(InspectOptions)
+	return opts, ok
+}
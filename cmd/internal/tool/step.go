@@ -17,6 +17,7 @@ const (
 	StepDiff
 	StepDeploy
 	StepInspect
+	StepDestroy
 )
 
 var stepNames = [...]string{
@@ -32,6 +33,7 @@ var stepNames = [...]string{
 	StepDiff:      "diff",
 	StepDeploy:    "deploy",
 	StepInspect:   "inspect",
+	StepDestroy:   "destroy",
 }
 
 func (s Step) String() string {
@@ -53,11 +55,11 @@ var ReleaseSteps = []Step{StepRelease}
 
 var PreflightSteps = []Step{StepDoctor, StepGen, StepFmt, StepLint, StepBuild, StepUnitTest}
 
-var InfraSteps = []Step{StepBootstrap, StepDiff, StepDeploy, StepInspect}
+var InfraSteps = []Step{StepBootstrap, StepDiff, StepDeploy, StepInspect, StepDestroy}
 
 var AllSteps = []Step{
 	StepInit, StepDoctor, StepGen, StepFmt, StepLint, StepBuild, StepUnitTest,
-	StepRelease, StepBootstrap, StepDiff, StepDeploy, StepInspect,
+	StepRelease, StepBootstrap, StepDiff, StepDeploy, StepInspect, StepDestroy,
 }
 
 func StepOrder() []Step {
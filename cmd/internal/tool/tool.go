@@ -87,6 +87,10 @@ type Deployer interface {
 	Deploy(ctx context.Context, dir string, r NodeReporter) error
 }
 
+type Destroyer interface {
+	Destroy(ctx context.Context, dir string, r NodeReporter) error
+}
+
 func RunStep(ctx context.Context, target Tool, step Step, dir string, r NodeReporter) error {
 	switch step {
 	case StepInit:
@@ -137,6 +141,10 @@ func RunStep(ctx context.Context, target Tool, step Step, dir string, r NodeRepo
 		if p, ok := target.(InspectionProvider); ok {
 			return RunInspections(ctx, p, dir, r)
 		}
+	case StepDestroy:
+		if d, ok := target.(Destroyer); ok {
+			return d.Destroy(ctx, dir, r)
+		}
 	default:
 		return errors.Newf("unknown step: %s", step)
 	}
@@ -211,6 +219,9 @@ func SupportsStep(target Tool, step Step) bool {
 	case StepInspect:
 		_, ok := target.(InspectionProvider)
 		return ok
+	case StepDestroy:
+		_, ok := target.(Destroyer)
+		return ok
 	default:
 		return false
 	}
@@ -283,6 +294,9 @@ type BootstrapOptions struct {
 	Profile             string
 	ExecutionPolicies   string
 	PermissionsBoundary string
+	// ShowTemplate prints the patched bootstrap template instead of
+	// deploying it.
+	ShowTemplate bool
 }
 
 type bootstrapOptionsKey struct{}
@@ -296,8 +310,31 @@ func BootstrapOptionsFrom(ctx context.Context) (BootstrapOptions, bool) {
 	return opts, ok
 }
 
+type DiffOptions struct {
+	Raw               bool
+	JSON              bool
+	FailOnReplacement bool
+}
+
+type diffOptionsKey struct{}
+
+func WithDiffOptions(ctx context.Context, opts DiffOptions) context.Context {
+	return context.WithValue(ctx, diffOptionsKey{}, opts)
+}
+
+func DiffOptionsFrom(ctx context.Context) (DiffOptions, bool) {
+	opts, ok := ctx.Value(diffOptionsKey{}).(DiffOptions)
+	return opts, ok
+}
+
 type DeployOptions struct {
 	Hotswap bool
+	// Raw streams cdk's own output instead of the stack progress table.
+	Raw bool
+	// PlanFile receives the change sets created instead of deploying.
+	PlanFile string
+	// ApplyFile names a plan whose change sets are executed.
+	ApplyFile string
 }
 
 type binCheckerKey struct{}
@@ -325,6 +362,22 @@ func DeployOptionsFrom(ctx context.Context) (DeployOptions, bool) {
 	return opts, ok
 }
 
+type DestroyOptions struct {
+	Confirm         string
+	IKnowThisIsProd bool
+}
+
+type destroyOptionsKey struct{}
+
+func WithDestroyOptions(ctx context.Context, opts DestroyOptions) context.Context {
+	return context.WithValue(ctx, destroyOptionsKey{}, opts)
+}
+
+func DestroyOptionsFrom(ctx context.Context) (DestroyOptions, bool) {
+	opts, ok := ctx.Value(destroyOptionsKey{}).(DestroyOptions)
+	return opts, ok
+}
+
 type ReleaseOptions struct {
 	DryRun bool
 }
@@ -194,3 +194,37 @@ func TestDeploymentContextMissing(t *testing.T) {
 		t.Errorf("expected empty string, got %q", d)
 	}
 }
+
+type lensProvider []tool.Inspection
+
+func (p lensProvider) Inspections() []tool.Inspection { return p }
+
+func TestRunInspectionsOptIn(t *testing.T) {
+	t.Parallel()
+
+	var ran []string
+	lens := func(name string, optIn bool) tool.Inspection {
+		return tool.Inspection{Name: name, OptIn: optIn, Run: func(context.Context, string, tool.NodeReporter) error {
+			ran = append(ran, name)
+			return nil
+		}}
+	}
+	p := lensProvider{lens("endpoints", false), lens("drift", true)}
+	r := tool.NopReporter().ForNode("", "", "")
+
+	if err := tool.RunInspections(context.Background(), p, "", r); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(ran, ",") != "endpoints" {
+		t.Errorf("default run: got %v, want only endpoints", ran)
+	}
+
+	ran = nil
+	ctx := tool.WithInspectSelection(context.Background(), []string{"drift"})
+	if err := tool.RunInspections(ctx, p, "", r); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(ran, ",") != "drift" {
+		t.Errorf("selected run: got %v, want drift", ran)
+	}
+}